@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+)
+
+// RedirectData holds data for the redirect template rendered at each alias
+type RedirectData struct {
+	Lang  string
+	CSP   string
+	Title string
+	URL   string
+}
+
+// checkAliases returns an error when an alias is not a valid slug, would
+// overwrite a generated page, or matches a note slug or an alias of another
+// note, since each alias is written to its own output path
+func checkAliases(notes []Note) error {
+	owners := make(map[string]string, len(notes))
+	for _, note := range notes {
+		owners[note.Slug] = note.Slug
+	}
+	for _, note := range notes {
+		for _, alias := range note.Aliases {
+			if !slugPattern.MatchString(alias) {
+				return fmt.Errorf("alias %q in %s should only contain lowercase letters, numbers, and hyphens, with / between segments", alias, note.Source)
+			}
+			if err := checkSlugPath(alias); err != nil {
+				return fmt.Errorf("alias %q in %s %w", alias, note.Source, err)
+			}
+			if owner, ok := owners[alias]; ok {
+				if owner == alias {
+					return fmt.Errorf("alias %q in %s collides with a note slug", alias, note.Source)
+				}
+				return fmt.Errorf("alias %q in %s is already an alias of %q", alias, note.Source, owner)
+			}
+			owners[alias] = note.Slug
+		}
+	}
+	return nil
+}
+
+// generateAliasPages writes alias/index.html for every alias of every note,
+// redirecting to the note's canonical URL
+func generateAliasPages(cfg Config, tmpl *template.Template, notes []Note) error {
+	for _, note := range notes {
+		for _, alias := range note.Aliases {
+			data := RedirectData{
+				Lang:  note.Lang,
+				CSP:   cfg.CSP,
+				Title: note.Title,
+				URL:   noteCanonicalURL(cfg, note.Slug),
+			}
+			if err := writeRedirectPage(tmpl, filepath.Join(cfg.OutputDir, alias), data); err != nil {
+				return fmt.Errorf("generating alias %s for %s: %w", alias, note.Slug, err)
+			}
+		}
+	}
+	return nil
+}
+
+// writeRedirectPage renders the redirect template to dir/index.html
+func writeRedirectPage(tmpl *template.Template, dir string, data RedirectData) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	f, err := os.Create(filepath.Join(dir, "index.html"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return tmpl.Execute(f, data)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckAliases(t *testing.T) {
+	tests := []struct {
+		name    string
+		notes   []Note
+		wantErr string
+	}{
+		{
+			name: "unique",
+			notes: []Note{
+				{Slug: "new-name", Aliases: []string{"old-name"}, Source: "content/new-name.yaml"},
+				{Slug: "other", Aliases: []string{"older"}, Source: "content/other.yaml"},
+			},
+		},
+		{
+			name: "collides with slug",
+			notes: []Note{
+				{Slug: "a", Aliases: []string{"b"}, Source: "content/a.yaml"},
+				{Slug: "b", Source: "content/b.yaml"},
+			},
+			wantErr: "collides with a note slug",
+		},
+		{
+			name: "collides with alias",
+			notes: []Note{
+				{Slug: "a", Aliases: []string{"old"}, Source: "content/a.yaml"},
+				{Slug: "b", Aliases: []string{"old"}, Source: "content/b.yaml"},
+			},
+			wantErr: `already an alias of "a"`,
+		},
+		{
+			name:    "reserved root",
+			notes:   []Note{{Slug: "a", Aliases: []string{"archive"}, Source: "content/a.yaml"}},
+			wantErr: "collides with the generated /archive/ pages",
+		},
+		{
+			name:    "reserved nested root",
+			notes:   []Note{{Slug: "a", Aliases: []string{"page/2"}, Source: "content/a.yaml"}},
+			wantErr: "collides with the generated /page/ pages",
+		},
+		{
+			name:    "path traversal",
+			notes:   []Note{{Slug: "a", Aliases: []string{"../escaped"}, Source: "content/a.yaml"}},
+			wantErr: `alias "../escaped" in content/a.yaml should only contain`,
+		},
+		{
+			name:    "empty",
+			notes:   []Note{{Slug: "a", Aliases: []string{""}, Source: "content/a.yaml"}},
+			wantErr: `alias "" in content/a.yaml should only contain`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkAliases(tt.notes)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestGenerateAliasPages(t *testing.T) {
+	tmpl, err := parseTemplate(siteFS, templateFuncs(Config{}, nil), "templates/redirect.html")
+	if err != nil {
+		t.Fatalf("Failed to parse redirect template: %v", err)
+	}
+
+	cfg := testConfig(t)
+	notes := []Note{{Slug: "new-name", Title: "New Name", Aliases: []string{"old-name"}}}
+	if err := generateAliasPages(cfg, tmpl, notes); err != nil {
+		t.Fatalf("generateAliasPages failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(cfg.OutputDir, "old-name", "index.html"))
+	if err != nil {
+		t.Fatalf("expected alias page: %v", err)
+	}
+	for _, want := range []string{
+		`<meta http-equiv="refresh" content="0; url=https://notes.example.com/new-name/">`,
+		`<link rel="canonical" href="https://notes.example.com/new-name/">`,
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("alias page missing %q:\n%s", want, content)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"html/template"
+	"os"
+	"path/filepath"
+)
+
+// AllNotesData holds data for the single page listing every note in full
+type AllNotesData struct {
+	Lang      string
+	CSP       string
+	SiteTitle string
+	Notes     []Note
+	Highlight bool
+	Footer    FooterData
+}
+
+// generateAllNotes writes every note that may be indexed, in the index
+// order, to all/index.html as one document for printing or saving as PDF
+func generateAllNotes(cfg Config, tmpl *template.Template, notes []Note) error {
+	dir := filepath.Join(cfg.OutputDir, "all")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	f, err := os.Create(filepath.Join(dir, "index.html"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	indexable := indexableNotes(notes)
+	data := AllNotesData{
+		Lang:      cfg.DefaultLang,
+		CSP:       cfg.CSP,
+		SiteTitle: cfg.SiteTitle,
+		Notes:     indexable,
+		Highlight: hasHighlightedExamples(indexable),
+		Footer:    newFooterData(cfg),
+	}
+	return tmpl.Execute(f, data)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateAllNotes(t *testing.T) {
+	cfg := testConfig(t)
+	tmpl, err := parseTemplate(siteFS, templateFuncs(cfg, nil), "templates/all.html", "templates/footer.html")
+	if err != nil {
+		t.Fatalf("Failed to parse all notes template: %v", err)
+	}
+
+	notes := []Note{
+		{Slug: "first", Title: "First", ThesisHTML: "First thesis.", BulletsHTML: []template.HTML{"A <em>point</em>"}, ExampleHTML: "<p>An example</p>"},
+		{Slug: "go/nested", Title: "Nested", ThesisHTML: "Nested thesis."},
+		{Slug: "hidden", Title: "Hidden", NoIndex: true},
+		{Slug: "draft", Title: "Draft", Draft: true},
+	}
+	if err := generateAllNotes(cfg, tmpl, notes); err != nil {
+		t.Fatalf("generateAllNotes returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(cfg.OutputDir, "all", "index.html"))
+	if err != nil {
+		t.Fatalf("Failed to read all notes page: %v", err)
+	}
+	content := string(data)
+	for _, want := range []string{
+		`<li><a href="#note-first">First</a></li>`,
+		// Browsers decode the escaped slash when matching the fragment to an id
+		`<li><a href="#note-go%2fnested">Nested</a></li>`,
+		`<article class="all-note" id="note-first">`,
+		`<article class="all-note" id="note-go/nested">`,
+		`<li>A <em>point</em></li>`,
+		`<p>An example</p>`,
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("all notes page does not contain %s", want)
+		}
+	}
+	if strings.Index(content, "note-first") > strings.Index(content, "note-go/nested") {
+		t.Error("notes should keep the index order")
+	}
+	for _, hidden := range []string{"Hidden", "Draft"} {
+		if strings.Contains(content, hidden) {
+			t.Errorf("all notes page should not include %s", hidden)
+		}
+	}
+}
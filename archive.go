@@ -0,0 +1,94 @@
+package main
+
+import (
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// archiveOtherBucket groups notes whose titles do not start with a letter A–Z
+const archiveOtherBucket = "#"
+
+// ArchiveGroup is the set of notes whose titles share a first letter
+type ArchiveGroup struct {
+	Letter string
+	Notes  []Note
+}
+
+// ArchiveData holds data for the archive template
+type ArchiveData struct {
+	Lang      string
+	CSP       string
+	SiteTitle string
+	Groups    []ArchiveGroup
+	Footer    FooterData
+}
+
+// archiveLetter returns the bucket for a title: its uppercase first letter
+// when that is A–Z, otherwise archiveOtherBucket
+func archiveLetter(title string) string {
+	r, _ := utf8.DecodeRuneInString(strings.TrimSpace(title))
+	r = unicode.ToUpper(r)
+	if r >= 'A' && r <= 'Z' {
+		return string(r)
+	}
+	return archiveOtherBucket
+}
+
+// archiveGroups buckets notes by the first letter of their title. Groups are
+// ordered A–Z with archiveOtherBucket last, and notes within a group are
+// sorted by title case-insensitively.
+func archiveGroups(notes []Note) []ArchiveGroup {
+	buckets := make(map[string][]Note)
+	for _, note := range notes {
+		letter := archiveLetter(note.Title)
+		buckets[letter] = append(buckets[letter], note)
+	}
+
+	groups := make([]ArchiveGroup, 0, len(buckets))
+	for letter, bucket := range buckets {
+		sort.SliceStable(bucket, func(i, j int) bool {
+			ti, tj := strings.ToLower(bucket[i].Title), strings.ToLower(bucket[j].Title)
+			if ti != tj {
+				return ti < tj
+			}
+			return bucket[i].Slug < bucket[j].Slug
+		})
+		groups = append(groups, ArchiveGroup{Letter: letter, Notes: bucket})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		li, lj := groups[i].Letter, groups[j].Letter
+		if (li == archiveOtherBucket) != (lj == archiveOtherBucket) {
+			return lj == archiveOtherBucket
+		}
+		return li < lj
+	})
+	return groups
+}
+
+// generateArchive writes the alphabetical archive page to archive/index.html
+func generateArchive(cfg Config, tmpl *template.Template, notes []Note) error {
+	dir := filepath.Join(cfg.OutputDir, "archive")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	f, err := os.Create(filepath.Join(dir, "index.html"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	data := ArchiveData{
+		Lang:      cfg.DefaultLang,
+		CSP:       cfg.CSP,
+		SiteTitle: cfg.SiteTitle,
+		Groups:    archiveGroups(publishedNotes(notes)),
+		Footer:    newFooterData(cfg),
+	}
+	return tmpl.Execute(f, data)
+}
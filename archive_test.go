@@ -0,0 +1,42 @@
+package main
+
+import "testing"
+
+func TestArchiveGroups(t *testing.T) {
+	notes := []Note{
+		{Slug: "b2", Title: "banana"},
+		{Slug: "a1", Title: "Apple"},
+		{Slug: "n1", Title: "2024 review"},
+		{Slug: "b1", Title: "Blueberry"},
+		{Slug: "a2", Title: "avocado"},
+		{Slug: "e1", Title: "Élan"},
+	}
+
+	groups := archiveGroups(notes)
+	want := []struct {
+		letter string
+		slugs  []string
+	}{
+		{"A", []string{"a1", "a2"}},
+		{"B", []string{"b2", "b1"}},
+		{"#", []string{"n1", "e1"}},
+	}
+	if len(groups) != len(want) {
+		t.Fatalf("expected %d groups, got %d: %v", len(want), len(groups), groups)
+	}
+	for i, w := range want {
+		if groups[i].Letter != w.letter {
+			t.Errorf("group %d letter = %q, want %q", i, groups[i].Letter, w.letter)
+			continue
+		}
+		if len(groups[i].Notes) != len(w.slugs) {
+			t.Errorf("group %s has %d notes, want %d", w.letter, len(groups[i].Notes), len(w.slugs))
+			continue
+		}
+		for j, slug := range w.slugs {
+			if groups[i].Notes[j].Slug != slug {
+				t.Errorf("group %s note %d = %q, want %q", w.letter, j, groups[i].Notes[j].Slug, slug)
+			}
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"html/template"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// fingerprintExtensions are the static asset types renamed with a content hash
+var fingerprintExtensions = map[string]bool{".css": true, ".js": true}
+
+// fingerprintLength is the number of hex characters of the hash kept in names
+const fingerprintLength = 10
+
+// isFingerprinted reports whether the static file at name is fingerprinted
+func isFingerprinted(name string) bool {
+	return fingerprintExtensions[path.Ext(name)]
+}
+
+// fingerprint returns name with a hash of data inserted before the extension,
+// so style.css becomes style.<hash>.css
+func fingerprint(name string, data []byte) string {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])[:fingerprintLength]
+	ext := path.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + hash + ext
+}
+
+// writeAssetManifest writes the mapping of original to fingerprinted asset paths
+func writeAssetManifest(outDir string, assets map[string]string) error {
+	data, err := json.MarshalIndent(assets, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(outDir, "asset-manifest.json"), data, 0644)
+}
+
+// templateFuncs returns the functions available to templates, with site
+// paths prefixed by the configured base path:
+//
+//   - asset "style.css" returns the site path of a static file, using its
+//     fingerprinted name when it has one
+//   - relURL "/archive/" returns the site path for a root-relative path;
+//     absolute URLs are returned unchanged
+//   - noteURL "slug" returns the site path of a note page
+//   - tagURL "go" returns the site path of a tag page
+//   - bulletID 0 returns the anchor ID of a note's first bullet
+//   - formatDate "2006-01-02" returns a note date in display form, such as
+//     "January 2, 2006"
+//   - parseDate "2006-01-02" returns a note date as a time, or the zero time
+//     when it is invalid
+//   - lastModified .Note returns the updated date of a note, or else its date
+//   - relativeTime returns how long before the build a time was, such as
+//     "3 days ago"
+//   - now returns the build time, e.g. {{now.Year}}
+func templateFuncs(cfg Config, assets map[string]string) template.FuncMap {
+	basePath := cfg.BasePath
+	return template.FuncMap{
+		"formatDate":   formatDate,
+		"parseDate":    parseDate,
+		"lastModified": lastModified,
+		"relativeTime": func(t time.Time) string {
+			return relativeTime(t, cfg.BuildTime)
+		},
+		"now": func() time.Time { return cfg.BuildTime },
+		"asset": func(name string) string {
+			if hashed, ok := assets[name]; ok {
+				return basePath + "/" + hashed
+			}
+			return basePath + "/" + name
+		},
+		"relURL": func(p string) string {
+			return relURL(basePath, p)
+		},
+		"noteURL": func(slug string) string {
+			return basePath + notePath(cfg, slug)
+		},
+		"bulletID": bulletID,
+		"tagURL": func(tag string) string {
+			return basePath + tagPath(tag)
+		},
+	}
+}
+
+// relURL prefixes a root-relative path with basePath, leaving relative paths,
+// protocol-relative URLs, and absolute URLs unchanged
+func relURL(basePath, p string) string {
+	if !strings.HasPrefix(p, "/") || strings.HasPrefix(p, "//") {
+		return p
+	}
+	return basePath + p
+}
+
+// parseTemplate parses files from fsys into a template named after the first
+// file, with funcs available to all of them
+func parseTemplate(fsys fs.FS, funcs template.FuncMap, files ...string) (*template.Template, error) {
+	return template.New(path.Base(files[0])).Funcs(funcs).ParseFS(fsys, files...)
+}
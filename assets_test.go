@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"html/template"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFingerprint(t *testing.T) {
+	first := fingerprint("css/style.css", []byte("body { color: black; }"))
+	again := fingerprint("css/style.css", []byte("body { color: black; }"))
+	changed := fingerprint("css/style.css", []byte("body { color: white; }"))
+
+	if !strings.HasPrefix(first, "css/style.") || !strings.HasSuffix(first, ".css") {
+		t.Errorf("fingerprint should keep the directory, name, and extension, got %q", first)
+	}
+	if first != again {
+		t.Errorf("fingerprint should be stable for identical content: %q != %q", first, again)
+	}
+	if first == changed {
+		t.Errorf("fingerprint should change when content changes, got %q for both", first)
+	}
+}
+
+func TestAssetFunc(t *testing.T) {
+	asset := templateFuncs(Config{}, map[string]string{"style.css": "style.0123456789.css"})["asset"].(func(string) string)
+
+	if got := asset("style.css"); got != "/style.0123456789.css" {
+		t.Errorf(`asset("style.css") = %q, want "/style.0123456789.css"`, got)
+	}
+	if got := asset("images/logo.png"); got != "/images/logo.png" {
+		t.Errorf(`asset("images/logo.png") = %q, want "/images/logo.png"`, got)
+	}
+}
+
+func TestFormatDateFunc(t *testing.T) {
+	tmpl := template.Must(template.New("t").Funcs(templateFuncs(Config{}, nil)).Parse(`{{formatDate .}}`))
+
+	tests := []struct {
+		date string
+		want string
+	}{
+		{"2024-03-09", "March 9, 2024"},
+		{"2006-01-02", "January 2, 2006"},
+		{"not a date", "not a date"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, tt.date); err != nil {
+			t.Fatalf("executing formatDate: %v", err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("formatDate(%q) = %q, want %q", tt.date, got, tt.want)
+		}
+	}
+}
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2025, 6, 15, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		date string
+		want string
+	}{
+		{"2025-06-16", "today"},
+		{"2025-06-15", "today"},
+		{"2025-06-14", "yesterday"},
+		{"2025-06-12", "3 days ago"},
+		{"2025-06-02", "13 days ago"},
+		{"2025-06-01", "2 weeks ago"},
+		{"2025-04-17", "8 weeks ago"},
+		{"2025-04-16", "2 months ago"},
+		{"2024-06-16", "12 months ago"},
+		{"2024-06-15", "1 year ago"},
+		{"2020-01-01", "5 years ago"},
+	}
+	for _, tt := range tests {
+		if got := relativeTime(parseDate(tt.date), now); got != tt.want {
+			t.Errorf("relativeTime(%s) = %q, want %q", tt.date, got, tt.want)
+		}
+	}
+}
+
+func TestRelativeTimeFunc(t *testing.T) {
+	cfg := Config{BuildTime: time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC)}
+	tmpl := template.Must(template.New("t").Funcs(templateFuncs(cfg, nil)).Parse(`{{relativeTime (parseDate .)}}`))
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, "2025-06-14"); err != nil {
+		t.Fatalf("executing relativeTime: %v", err)
+	}
+	if got := buf.String(); got != "yesterday" {
+		t.Errorf("relativeTime = %q, want yesterday", got)
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import "strings"
+
+// Breadcrumb is one step in the trail from the homepage to a nested note
+type Breadcrumb struct {
+	Title   string
+	URL     string
+	Current bool
+}
+
+// noteBreadcrumbs returns the trail from the homepage to note, one step per
+// slug segment. Parent segments link to the note at that path when there is
+// one, titled from titles, and otherwise show the bare segment. Notes with a
+// single segment slug have no breadcrumbs.
+func noteBreadcrumbs(cfg Config, note Note, titles map[string]string) []Breadcrumb {
+	segments := strings.Split(note.Slug, "/")
+	if len(segments) < 2 {
+		return nil
+	}
+
+	crumbs := []Breadcrumb{{Title: "Notes", URL: "/"}}
+	for i := 1; i < len(segments); i++ {
+		parent := strings.Join(segments[:i], "/")
+		crumb := Breadcrumb{Title: segments[i-1]}
+		if title, ok := titles[parent]; ok {
+			crumb = Breadcrumb{Title: title, URL: notePath(cfg, parent)}
+		}
+		crumbs = append(crumbs, crumb)
+	}
+	return append(crumbs, Breadcrumb{Title: note.Title, Current: true})
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNoteBreadcrumbs(t *testing.T) {
+	cfg := defaultConfig()
+	titles := map[string]string{"go": "Go", "go/concurrency": "Concurrency"}
+
+	if crumbs := noteBreadcrumbs(cfg, Note{Slug: "go", Title: "Go"}, titles); crumbs != nil {
+		t.Errorf("expected no breadcrumbs for a single segment slug, got %+v", crumbs)
+	}
+
+	got := noteBreadcrumbs(cfg, Note{Slug: "go/concurrency", Title: "Concurrency"}, titles)
+	want := []Breadcrumb{
+		{Title: "Notes", URL: "/"},
+		{Title: "Go", URL: "/go/"},
+		{Title: "Concurrency", Current: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("noteBreadcrumbs() = %+v, want %+v", got, want)
+	}
+
+	got = noteBreadcrumbs(cfg, Note{Slug: "rust/ownership", Title: "Ownership"}, titles)
+	want = []Breadcrumb{
+		{Title: "Notes", URL: "/"},
+		{Title: "rust"},
+		{Title: "Ownership", Current: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("noteBreadcrumbs() without a parent note = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Category is the set of notes sharing a primary category
+type Category struct {
+	Name  string
+	Slug  string
+	Notes []Note
+}
+
+// CategoryPageData holds data for the category template
+type CategoryPageData struct {
+	Lang      string
+	CSP       string
+	SiteTitle string
+	Category  Category
+	Footer    FooterData
+}
+
+// categoryPath returns the site-relative path of a category page
+func categoryPath(slug string) string {
+	return "/category/" + slug + "/"
+}
+
+// checkCategories returns an error when two category names map to the same
+// path or, if required is set, when a note has no category
+func checkCategories(notes []Note, required bool) error {
+	names := make(map[string]string)
+	for _, note := range notes {
+		if note.Category == "" {
+			if required {
+				return fmt.Errorf("%s has no category", note.Source)
+			}
+			continue
+		}
+
+		slug := slugify(note.Category)
+		if slug == "" {
+			return fmt.Errorf("category %q in %s has no letters or digits to build a path from", note.Category, note.Source)
+		}
+		if name, ok := names[slug]; ok && name != note.Category {
+			return fmt.Errorf("category %q in %s and category %q share the path %s", note.Category, note.Source, name, categoryPath(slug))
+		}
+		names[slug] = note.Category
+	}
+	return nil
+}
+
+// buildCategories groups notes by category, sorted by category path with
+// the notes of each category in the order of notes
+func buildCategories(notes []Note) []Category {
+	bySlug := make(map[string]*Category)
+	for _, note := range notes {
+		if note.Category == "" {
+			continue
+		}
+		slug := slugify(note.Category)
+		if bySlug[slug] == nil {
+			bySlug[slug] = &Category{Name: note.Category, Slug: slug}
+		}
+		bySlug[slug].Notes = append(bySlug[slug].Notes, note)
+	}
+
+	categories := make([]Category, 0, len(bySlug))
+	for _, c := range bySlug {
+		categories = append(categories, *c)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		return categories[i].Slug < categories[j].Slug
+	})
+	return categories
+}
+
+// generateCategoryPages writes category/<slug>/index.html listing the notes
+// in each category
+func generateCategoryPages(cfg Config, tmpl *template.Template, categories []Category) error {
+	footer := newFooterData(cfg)
+	for _, c := range categories {
+		dir := filepath.Join(cfg.OutputDir, "category", c.Slug)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+
+		data := CategoryPageData{Lang: cfg.DefaultLang, CSP: cfg.CSP, SiteTitle: cfg.SiteTitle, Category: c, Footer: footer}
+		if err := writeCategoryPage(tmpl, filepath.Join(dir, "index.html"), data); err != nil {
+			return fmt.Errorf("generating category %s: %w", c.Slug, err)
+		}
+	}
+	return nil
+}
+
+func writeCategoryPage(tmpl *template.Template, path string, data CategoryPageData) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return tmpl.Execute(f, data)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckCategories(t *testing.T) {
+	tests := []struct {
+		name     string
+		notes    []Note
+		required bool
+		wantErr  string
+	}{
+		{
+			name: "optional",
+			notes: []Note{
+				{Slug: "a", Category: "Engineering", Source: "content/a.yaml"},
+				{Slug: "b", Source: "content/b.yaml"},
+			},
+		},
+		{
+			name: "required and missing",
+			notes: []Note{
+				{Slug: "a", Category: "Engineering", Source: "content/a.yaml"},
+				{Slug: "b", Source: "content/b.yaml"},
+			},
+			required: true,
+			wantErr:  "content/b.yaml has no category",
+		},
+		{
+			name: "colliding paths",
+			notes: []Note{
+				{Slug: "a", Category: "Product Management", Source: "content/a.yaml"},
+				{Slug: "b", Category: "product management", Source: "content/b.yaml"},
+			},
+			wantErr: "share the path /category/product-management/",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkCategories(tt.notes, tt.required)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestGenerateCategoryPages(t *testing.T) {
+	tmpl, err := parseTemplate(siteFS, templateFuncs(Config{}, nil), "templates/category.html", "templates/footer.html")
+	if err != nil {
+		t.Fatalf("Failed to parse category template: %v", err)
+	}
+
+	cfg := testConfig(t)
+	categories := buildCategories([]Note{
+		{Slug: "first", Title: "First", Category: "Engineering"},
+		{Slug: "other", Title: "Other", Category: "Leadership"},
+		{Slug: "uncategorized", Title: "Uncategorized"},
+		{Slug: "second", Title: "Second", Category: "Engineering"},
+	})
+	if len(categories) != 2 || categories[0].Slug != "engineering" || categories[1].Slug != "leadership" {
+		t.Fatalf("unexpected categories %+v", categories)
+	}
+	if err := generateCategoryPages(cfg, tmpl, categories); err != nil {
+		t.Fatalf("generateCategoryPages failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(cfg.OutputDir, "category", "engineering", "index.html"))
+	if err != nil {
+		t.Fatalf("expected category page: %v", err)
+	}
+	html := string(content)
+	first, second := strings.Index(html, `href="/first/"`), strings.Index(html, `href="/second/"`)
+	if first < 0 || second < 0 || first > second {
+		t.Errorf("category page should list its notes in order:\n%s", html)
+	}
+	if strings.Contains(html, "/other/") || strings.Contains(html, "/uncategorized/") {
+		t.Errorf("category page should only list notes in the category:\n%s", html)
+	}
+}
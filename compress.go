@@ -0,0 +1,91 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/andybalholm/brotli"
+)
+
+// compressMinSize is the smallest file worth precompressing; below this the
+// compressed copy saves too little to matter
+const compressMinSize = 1024
+
+// compressExtensions lists the text-like output files that are precompressed
+var compressExtensions = map[string]bool{
+	".html": true,
+	".xml":  true,
+	".json": true,
+	".css":  true,
+	".js":   true,
+	".txt":  true,
+}
+
+// compressOutput writes a .gz sibling, and a .br sibling when withBrotli is
+// set, for every text-like file in outDir of at least compressMinSize bytes.
+// It returns the number of files compressed.
+func compressOutput(outDir string, withBrotli bool) (int, error) {
+	var paths []string
+	err := filepath.WalkDir(outDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !compressExtensions[filepath.Ext(path)] {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.Size() >= compressMinSize {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, path := range paths {
+		if err := compressFile(path, ".gz", func(w io.Writer) io.WriteCloser {
+			zw, _ := gzip.NewWriterLevel(w, gzip.BestCompression)
+			return zw
+		}); err != nil {
+			return 0, err
+		}
+		if withBrotli {
+			if err := compressFile(path, ".br", func(w io.Writer) io.WriteCloser {
+				return brotli.NewWriterLevel(w, brotli.BestCompression)
+			}); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return len(paths), nil
+}
+
+// compressFile writes path compressed by the writer from newWriter to path+ext
+func compressFile(path, ext string, newWriter func(io.Writer) io.WriteCloser) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path + ext)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := newWriter(f)
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
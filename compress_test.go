@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+func TestCompressOutputRoundTrip(t *testing.T) {
+	outDir := t.TempDir()
+	page := []byte(strings.Repeat("<p>Hello, notes!</p>\n", 100))
+	small := []byte("{}")
+	files := map[string][]byte{
+		"index.html":    page,
+		"small.json":    small,
+		"image.png":     page,
+		"nested/a.html": page,
+	}
+	for name, data := range files {
+		path := filepath.Join(outDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	count, err := compressOutput(outDir, true)
+	if err != nil {
+		t.Fatalf("compressOutput failed: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 compressed files, got %d", count)
+	}
+
+	for _, name := range []string{"index.html", "nested/a.html"} {
+		gz, err := os.Open(filepath.Join(outDir, name+".gz"))
+		if err != nil {
+			t.Fatalf("expected gzip output for %s: %v", name, err)
+		}
+		zr, err := gzip.NewReader(gz)
+		if err != nil {
+			t.Fatalf("reading gzip header for %s: %v", name, err)
+		}
+		got, err := io.ReadAll(zr)
+		gz.Close()
+		if err != nil {
+			t.Fatalf("decompressing %s: %v", name, err)
+		}
+		if !bytes.Equal(got, page) {
+			t.Errorf("gzip round trip of %s does not match original", name)
+		}
+
+		br, err := os.ReadFile(filepath.Join(outDir, name+".br"))
+		if err != nil {
+			t.Fatalf("expected brotli output for %s: %v", name, err)
+		}
+		got, err = io.ReadAll(brotli.NewReader(bytes.NewReader(br)))
+		if err != nil {
+			t.Fatalf("decompressing %s: %v", name, err)
+		}
+		if !bytes.Equal(got, page) {
+			t.Errorf("brotli round trip of %s does not match original", name)
+		}
+	}
+
+	for _, name := range []string{"small.json.gz", "image.png.gz"} {
+		if _, err := os.Stat(filepath.Join(outDir, name)); !os.IsNotExist(err) {
+			t.Errorf("expected %s not to be written", name)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultConfigPath is the site configuration file read at startup
+const defaultConfigPath = "config.yaml"
+
+// Config holds site-wide settings loaded from config.yaml
+type Config struct {
+	BaseURL     string `yaml:"base_url"`
+	BasePath    string `yaml:"base_path"`
+	SiteTitle   string `yaml:"site_title"`
+	Description string `yaml:"description"`
+	Author      string `yaml:"author"`
+	DefaultLang string `yaml:"default_lang"`
+	OutputDir   string `yaml:"output_dir"`
+	ChangeFreq  string `yaml:"changefreq"`
+	Priority    string `yaml:"priority"`
+	CheckLinks  bool   `yaml:"check_links"`
+	Compress    bool   `yaml:"compress"`
+	Brotli      bool   `yaml:"brotli"`
+	Redirects   string `yaml:"redirects"`
+	Headers     string `yaml:"headers"`
+	LastMod     string `yaml:"lastmod"`
+	PageSize    int    `yaml:"page_size"`
+	PWA         bool   `yaml:"pwa"`
+	ThemeColor  string `yaml:"theme_color"`
+	URLStyle    string `yaml:"url_style"`
+	CSP         string `yaml:"csp"`
+
+	// DateLayout is the Go time layout the date, updated, publish_at, and
+	// expire_at fields of notes are written in
+	DateLayout string `yaml:"date_layout"`
+
+	// HomepageChangeFreq and HomepagePriority are the sitemap hints for the
+	// homepage
+	HomepageChangeFreq string `yaml:"homepage_changefreq"`
+	HomepagePriority   string `yaml:"homepage_priority"`
+
+	// RequireCategory fails the build when a note has no category
+	RequireCategory bool `yaml:"require_category"`
+
+	// Tags maps each canonical tag to aliases that are replaced by it
+	Tags map[string][]string `yaml:"tags"`
+
+	// RestrictTags fails the build when a note has a tag not listed in Tags
+	RestrictTags bool `yaml:"restrict_tags"`
+
+	// Strict fails the build when any warning is reported
+	Strict bool `yaml:"strict"`
+
+	// Incremental keeps the output directory between builds and skips note
+	// pages whose inputs are unchanged
+	Incremental bool `yaml:"incremental"`
+
+	// Images writes resized variants of cover images for responsive srcsets
+	Images bool `yaml:"images"`
+
+	// MaxLengths limits the length of note titles, theses, and bullets
+	MaxLengths FieldLimits `yaml:"max_lengths"`
+
+	// Icons are listed in the web app manifest when pwa is enabled
+	Icons []ManifestIcon `yaml:"icons"`
+
+	// Humans is written to humans.txt when present
+	Humans *HumansConfig `yaml:"humans"`
+
+	// Security is written to .well-known/security.txt when present
+	Security *SecurityConfig `yaml:"security"`
+
+	// LLMsTxt writes llms.txt indexing the notes for language models
+	LLMsTxt bool `yaml:"llms_txt"`
+
+	// StaticDirs are directories on disk copied after static/, in order, so
+	// files in later directories override earlier ones
+	StaticDirs []string `yaml:"static_dirs"`
+
+	// SecurityHeaders are sent with every path in the headers file
+	SecurityHeaders map[string]string `yaml:"security_headers"`
+
+	// EmbedHosts lists the hosts iframes in a note's html field may load from
+	EmbedHosts []string `yaml:"embed_hosts"`
+
+	// BuildTime is the instant the current build is stamped with, set by run
+	BuildTime time.Time `yaml:"-"`
+
+	// LastUpdated is when the site content last changed, set by run
+	LastUpdated time.Time `yaml:"-"`
+}
+
+// defaultConfig returns the settings used when config.yaml is absent
+func defaultConfig() Config {
+	return Config{
+		SiteTitle:   "UnitVectorY-Labs Notes",
+		Description: "Notes drawn from practice and experience",
+		OutputDir:   "output",
+		ChangeFreq:  "monthly",
+		Priority:    "0.8",
+		PageSize:    defaultPageSize,
+		DefaultLang: "en",
+		ThemeColor:  defaultThemeColor,
+		EmbedHosts:  defaultEmbedHosts,
+		URLStyle:    urlStyleBoth,
+		MaxLengths:  defaultFieldLimits,
+
+		DateLayout:         dateLayout,
+		HomepageChangeFreq: "weekly",
+		HomepagePriority:   "1.0",
+	}
+}
+
+// loadConfig reads the config file at path on top of the defaults, then
+// applies overrides from the environment:
+//
+//   - BASEURL overrides base_url
+//   - BASEPATH overrides base_path
+//   - SITE_TITLE overrides site_title
+//   - SITE_AUTHOR overrides author
+//   - OUTPUT_DIR overrides output_dir
+//   - DEFAULT_LANG overrides default_lang
+//   - URL_STYLE overrides url_style
+//   - CSP overrides csp
+//
+// A missing config file is not an error, but base_url must be set.
+func loadConfig(path string) (Config, error) {
+	cfg, err := readConfig(path)
+	if err == nil && cfg.BaseURL == "" {
+		err = fmt.Errorf("base_url must be set in %s or the BASEURL environment variable", path)
+	}
+	return cfg, err
+}
+
+// readConfig loads the config like loadConfig but leaves base_url empty when
+// it is not set, for commands such as lint that never write absolute URLs
+func readConfig(path string) (Config, error) {
+	cfg := defaultConfig()
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return cfg, fmt.Errorf("reading %s: %w", path, err)
+	}
+	if err == nil {
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return cfg, fmt.Errorf("parsing %s: %w", path, err)
+		}
+	}
+
+	overrides := []struct {
+		env   string
+		field *string
+	}{
+		{"BASEURL", &cfg.BaseURL},
+		{"BASEPATH", &cfg.BasePath},
+		{"SITE_TITLE", &cfg.SiteTitle},
+		{"SITE_AUTHOR", &cfg.Author},
+		{"OUTPUT_DIR", &cfg.OutputDir},
+		{"DEFAULT_LANG", &cfg.DefaultLang},
+		{"URL_STYLE", &cfg.URLStyle},
+		{"CSP", &cfg.CSP},
+	}
+	for _, o := range overrides {
+		if v := os.Getenv(o.env); v != "" {
+			*o.field = v
+		}
+	}
+
+	// Serve the site from base_path under base_url, so absolute URLs built
+	// from base_url include it
+	cfg.BasePath = normalizeBasePath(cfg.BasePath)
+	if cfg.BaseURL != "" {
+		cfg.BaseURL = strings.TrimSuffix(cfg.BaseURL, "/")
+		if !strings.HasSuffix(cfg.BaseURL, cfg.BasePath) {
+			cfg.BaseURL += cfg.BasePath
+		}
+	}
+	if err := validateSitemapHints(cfg.ChangeFreq, cfg.Priority); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := validateSitemapHints(cfg.HomepageChangeFreq, cfg.HomepagePriority); err != nil {
+		return cfg, fmt.Errorf("%s: homepage: %w", path, err)
+	}
+	if cfg.PageSize < 1 {
+		return cfg, fmt.Errorf("%s: page_size must be at least 1, got %d", path, cfg.PageSize)
+	}
+	if err := validateRedirects(cfg.Redirects); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := validateHeaders(cfg.Headers); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := validateLastMod(cfg.LastMod); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := validateSecurity(cfg.Security); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := validateURLStyle(cfg.URLStyle); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := validateTagRules(cfg.Tags); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := validateDateLayout(cfg.DateLayout); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	if l := cfg.MaxLengths; l.Title < 0 || l.Thesis < 0 || l.Bullet < 0 {
+		return cfg, fmt.Errorf("%s: max_lengths must not be negative", path)
+	}
+
+	return cfg, nil
+}
+
+// validateRedirects returns an error unless format is empty or a supported
+// host-specific redirects format
+func validateRedirects(format string) error {
+	if format != "" && format != redirectsNetlify {
+		return fmt.Errorf("unsupported redirects format %q (supported: %s)", format, redirectsNetlify)
+	}
+	return nil
+}
+
+// validateHeaders returns an error unless format is empty or a supported
+// host-specific headers format
+func validateHeaders(format string) error {
+	if format != "" && format != headersNetlify {
+		return fmt.Errorf("unsupported headers format %q (supported: %s)", format, headersNetlify)
+	}
+	return nil
+}
+
+// validateDateLayout returns an error unless layout formats and parses back a
+// date without losing its year, month, or day
+func validateDateLayout(layout string) error {
+	want := time.Date(2006, time.January, 2, 0, 0, 0, 0, time.UTC)
+	got, err := time.Parse(layout, want.Format(layout))
+	if err != nil || !got.Equal(want) {
+		return fmt.Errorf("date_layout %q should be a Go time layout with a year, month, and day, such as %q", layout, dateLayout)
+	}
+	return nil
+}
+
+// normalizeBasePath returns p with a single leading slash and no trailing
+// slash, or an empty string when p is empty or the root
+func normalizeBasePath(p string) string {
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return ""
+	}
+	return "/" + p
+}
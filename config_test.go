@@ -0,0 +1,154 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadConfigDefaults(t *testing.T) {
+	t.Setenv("BASEURL", "https://notes.example.com")
+	t.Setenv("BASEPATH", "")
+	t.Setenv("SITE_TITLE", "")
+	t.Setenv("SITE_AUTHOR", "")
+	t.Setenv("OUTPUT_DIR", "")
+	t.Setenv("DEFAULT_LANG", "")
+
+	cfg, err := loadConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil {
+		t.Fatalf("loadConfig returned error: %v", err)
+	}
+
+	want := defaultConfig()
+	want.BaseURL = "https://notes.example.com"
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("loadConfig() = %+v, want %+v", cfg, want)
+	}
+}
+
+func TestLoadConfigFileAndEnvOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := "base_url: https://file.example.com\nsite_title: From File\nauthor: File Author\nchangefreq: weekly\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("BASEURL", "https://env.example.com")
+	t.Setenv("BASEPATH", "")
+	t.Setenv("SITE_TITLE", "")
+	t.Setenv("SITE_AUTHOR", "")
+	t.Setenv("OUTPUT_DIR", "")
+	t.Setenv("DEFAULT_LANG", "")
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("loadConfig returned error: %v", err)
+	}
+
+	if cfg.BaseURL != "https://env.example.com" {
+		t.Errorf("BASEURL should override base_url, got %q", cfg.BaseURL)
+	}
+	if cfg.SiteTitle != "From File" || cfg.Author != "File Author" || cfg.ChangeFreq != "weekly" {
+		t.Errorf("file values should be used when not overridden, got %+v", cfg)
+	}
+	if cfg.Priority != "0.8" || cfg.OutputDir != "output" {
+		t.Errorf("defaults should fill fields absent from the file, got %+v", cfg)
+	}
+}
+
+func TestLoadConfigRequiresBaseURL(t *testing.T) {
+	t.Setenv("BASEURL", "")
+
+	if _, err := loadConfig(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected an error when no base URL is configured")
+	}
+}
+
+func TestReadConfigAllowsMissingBaseURL(t *testing.T) {
+	t.Setenv("BASEURL", "")
+	t.Setenv("BASEPATH", "")
+
+	cfg, err := readConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil {
+		t.Fatalf("readConfig returned error: %v", err)
+	}
+	if cfg.BaseURL != "" {
+		t.Errorf("expected an empty base URL, got %q", cfg.BaseURL)
+	}
+}
+
+func TestLoadConfigRejectsUnknownRedirects(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("redirects: apache\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("BASEURL", "https://notes.example.com")
+
+	if _, err := loadConfig(path); err == nil {
+		t.Error("expected an error for an unsupported redirects format")
+	}
+}
+
+func TestLoadConfigRejectsUnknownHeaders(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("headers: vercel\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("BASEURL", "https://notes.example.com")
+
+	if _, err := loadConfig(path); err == nil {
+		t.Error("expected an error for an unsupported headers format")
+	}
+}
+
+func TestValidateDateLayout(t *testing.T) {
+	for _, layout := range []string{"2006-01-02", "Jan 2, 2006", "02/01/2006"} {
+		if err := validateDateLayout(layout); err != nil {
+			t.Errorf("expected %q to be valid, got %v", layout, err)
+		}
+	}
+	for _, layout := range []string{"", "Jan 2006", "Jan 2", "not a layout"} {
+		if err := validateDateLayout(layout); err == nil {
+			t.Errorf("expected %q to be rejected", layout)
+		}
+	}
+}
+
+func TestLoadConfigBasePath(t *testing.T) {
+	tests := []struct {
+		baseURL, basePath string
+		wantURL, wantPath string
+	}{
+		{"https://example.com", "", "https://example.com", ""},
+		{"https://example.com/", "/", "https://example.com", ""},
+		{"https://example.com", "notes", "https://example.com/notes", "/notes"},
+		{"https://example.com/", "/notes/", "https://example.com/notes", "/notes"},
+		{"https://example.com/notes", "/notes", "https://example.com/notes", "/notes"},
+		{"https://example.com", "/a/b/", "https://example.com/a/b", "/a/b"},
+	}
+	for _, tt := range tests {
+		t.Setenv("BASEURL", tt.baseURL)
+		t.Setenv("BASEPATH", tt.basePath)
+
+		cfg, err := loadConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+		if err != nil {
+			t.Fatalf("loadConfig returned error: %v", err)
+		}
+		if cfg.BaseURL != tt.wantURL || cfg.BasePath != tt.wantPath {
+			t.Errorf("BASEURL=%q BASEPATH=%q gave base URL %q and path %q, want %q and %q",
+				tt.baseURL, tt.basePath, cfg.BaseURL, cfg.BasePath, tt.wantURL, tt.wantPath)
+		}
+	}
+}
+
+// testConfig returns the default config with a test base URL and a temporary
+// output directory
+func testConfig(t *testing.T) Config {
+	t.Helper()
+	cfg := defaultConfig()
+	cfg.BaseURL = "https://notes.example.com"
+	cfg.OutputDir = t.TempDir()
+	cfg.BuildTime = time.Now()
+	return cfg
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// noteDecoders maps each supported content file extension to the decoder
+// that unmarshals it into a Note. All formats share the same field names.
+var noteDecoders = map[string]func(data []byte, v any) error{
+	".yaml": yaml.Unmarshal,
+	".json": json.Unmarshal,
+	".toml": toml.Unmarshal,
+	".md":   decodeMarkdownNote,
+}
+
+// frontMatterDelimiter opens and closes the YAML front matter of a Markdown note
+const frontMatterDelimiter = "---"
+
+// errNoFrontMatter is returned for Markdown files that do not start with
+// front matter; they are treated as documentation rather than notes
+var errNoFrontMatter = errors.New("no front matter")
+
+// decodeMarkdownNote unmarshals the YAML front matter of a Markdown file into
+// v and, when v is a *Note, stores the Markdown after it in the body field
+func decodeMarkdownNote(data []byte, v any) error {
+	frontMatter, body, err := splitFrontMatter(data)
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(frontMatter, v); err != nil {
+		return err
+	}
+	if note, ok := v.(*Note); ok {
+		note.Body = string(bytes.TrimSpace(body))
+	}
+	return nil
+}
+
+// splitFrontMatter separates the front matter between the leading pair of
+// --- lines in data from the body that follows
+func splitFrontMatter(data []byte) (frontMatter, body []byte, err error) {
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	if string(bytes.TrimSpace(lines[0])) != frontMatterDelimiter {
+		return nil, nil, errNoFrontMatter
+	}
+	for i := 1; i < len(lines); i++ {
+		if string(bytes.TrimSpace(lines[i])) == frontMatterDelimiter {
+			return bytes.Join(lines[1:i], nil), bytes.Join(lines[i+1:], nil), nil
+		}
+	}
+	return nil, nil, errors.New("front matter is not closed with ---")
+}
+
+// slugPattern matches valid slugs: one or more segments of lowercase
+// letters, numbers, and hyphens, separated by slashes
+var slugPattern = regexp.MustCompile(`^[a-z0-9-]+(/[a-z0-9-]+)*$`)
+
+// FieldLimits caps the length in characters of the note fields shown on
+// index cards and note pages. A limit of 0 disables the check.
+type FieldLimits struct {
+	Title  int `yaml:"title"`
+	Thesis int `yaml:"thesis"`
+	Bullet int `yaml:"bullet"`
+}
+
+// defaultFieldLimits keeps notes from overflowing the card layout
+var defaultFieldLimits = FieldLimits{Title: 80, Thesis: 300, Bullet: 200}
+
+// validateNote checks the invariants every note must meet to be built: a
+// slug, title, and thesis, a slug using only the allowed characters, alt
+// text for any image, fields within limits, and a content file named after
+// the slug in any supported format. A nested slug such as go/concurrency
+// must be read from a path ending in go/concurrency.
+func validateNote(note Note, filename string, limits FieldLimits) error {
+	if note.Slug == "" {
+		return errors.New("slug is required")
+	}
+	if !slugPattern.MatchString(note.Slug) {
+		return fmt.Errorf("slug %q should only contain lowercase letters, numbers, and hyphens, with / between segments", note.Slug)
+	}
+	if note.ID != "" && !idPattern.MatchString(note.ID) {
+		return fmt.Errorf("id %q should only contain letters, numbers, and hyphens", note.ID)
+	}
+	if note.Title == "" {
+		return errors.New("title is required")
+	}
+	if note.Thesis == "" {
+		return errors.New("thesis is required")
+	}
+	if err := checkHeadExtra(note.HeadExtra); err != nil {
+		return err
+	}
+	if note.Image != "" && strings.TrimSpace(note.ImageAlt) == "" {
+		return errors.New("image_alt is required when image is set")
+	}
+	if err := checkFieldLength("title", note.Title, limits.Title); err != nil {
+		return err
+	}
+	if err := checkFieldLength("thesis", note.Thesis, limits.Thesis); err != nil {
+		return err
+	}
+	for i, bullet := range note.Bullets {
+		if err := checkFieldLength(fmt.Sprintf("bullet %d", i+1), bullet, limits.Bullet); err != nil {
+			return err
+		}
+	}
+	if err := checkFieldLength("description", note.Description, maxDescriptionLength); err != nil {
+		return err
+	}
+
+	expected := note.Slug + filepath.Ext(filename)
+	actual := filepath.ToSlash(filename)
+	if actual != expected && !strings.HasSuffix(actual, "/"+expected) {
+		return fmt.Errorf("filename %q does not match slug %q (expected %q)", filepath.Base(filename), note.Slug, expected)
+	}
+	return nil
+}
+
+// checkFieldLength returns an error naming field when value is longer than
+// limit characters
+func checkFieldLength(field, value string, limit int) error {
+	if n := utf8.RuneCountInString(value); limit > 0 && n > limit {
+		return fmt.Errorf("%s is %d characters, over the limit of %d", field, n, limit)
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestReadNotesFormats(t *testing.T) {
+	fsys := fstest.MapFS{
+		"content/yaml-note.yaml": {Data: []byte(`slug: yaml-note
+title: YAML Note
+thesis: Written in YAML.
+tags: [format]
+links:
+  - label: Go
+    url: https://go.dev
+`)},
+		"content/json-note.json": {Data: []byte(`{
+  "slug": "json-note",
+  "title": "JSON Note",
+  "thesis": "Written in JSON.",
+  "tags": ["format"],
+  "links": [{"label": "Go", "url": "https://go.dev"}]
+}`)},
+		"content/nested/toml-note.toml": {Data: []byte(`slug = "toml-note"
+title = "TOML Note"
+thesis = "Written in TOML."
+tags = ["format"]
+
+[[links]]
+label = "Go"
+url = "https://go.dev"
+`)},
+		"content/README.md": {Data: []byte("not a note")},
+	}
+
+	notes, err := readNotes(testConfig(t), fsys)
+	if err != nil {
+		t.Fatalf("readNotes failed: %v", err)
+	}
+	if len(notes) != 3 {
+		t.Fatalf("expected 3 notes, got %d", len(notes))
+	}
+
+	for _, note := range notes {
+		if note.Title == "" || note.Thesis == "" {
+			t.Errorf("%s: expected title and thesis to be decoded, got %+v", note.Source, note)
+		}
+		if len(note.Tags) != 1 || note.Tags[0] != "format" {
+			t.Errorf("%s: expected tags [format], got %v", note.Source, note.Tags)
+		}
+		if len(note.Links) != 1 || note.Links[0].URL != "https://go.dev" {
+			t.Errorf("%s: expected one link to https://go.dev, got %v", note.Source, note.Links)
+		}
+		if note.Theme != defaultTheme {
+			t.Errorf("%s: expected defaults to be applied, got theme %q", note.Source, note.Theme)
+		}
+	}
+}
+
+func TestReadNotesDefaultsLang(t *testing.T) {
+	fsys := fstest.MapFS{
+		"content/default.yaml": {Data: []byte("slug: default\ntitle: Default\nthesis: Uses the site language.\n")},
+		"content/german.yaml":  {Data: []byte("slug: german\ntitle: Deutsch\nthesis: Auf Deutsch.\nlang: de\n")},
+	}
+
+	cfg := testConfig(t)
+	cfg.DefaultLang = "en-GB"
+	notes, err := readNotes(cfg, fsys)
+	if err != nil {
+		t.Fatalf("readNotes failed: %v", err)
+	}
+
+	langs := make(map[string]string)
+	for _, note := range notes {
+		langs[note.Slug] = note.Lang
+	}
+	if langs["default"] != "en-GB" {
+		t.Errorf("expected default note to use the site language, got %q", langs["default"])
+	}
+	if langs["german"] != "de" {
+		t.Errorf("expected lang field to be kept, got %q", langs["german"])
+	}
+}
+
+func TestValidateNote(t *testing.T) {
+	valid := Note{Slug: "poc-vs-mvp2", Title: "PoC vs MVP", Thesis: "Different goals."}
+	tests := []struct {
+		name     string
+		edit     func(*Note)
+		filename string
+		wantErr  string
+	}{
+		{"valid yaml", func(*Note) {}, "content/poc-vs-mvp2.yaml", ""},
+		{"valid nested json", func(*Note) {}, "content/a/b/poc-vs-mvp2.json", ""},
+		{"missing slug", func(n *Note) { n.Slug = "" }, "content/poc-vs-mvp2.yaml", "slug is required"},
+		{"uppercase slug", func(n *Note) { n.Slug = "PoC" }, "content/PoC.yaml", "lowercase"},
+		{"slug with space", func(n *Note) { n.Slug = "poc mvp" }, "content/poc mvp.yaml", "lowercase"},
+		{"missing title", func(n *Note) { n.Title = "" }, "content/poc-vs-mvp2.yaml", "title is required"},
+		{"missing thesis", func(n *Note) { n.Thesis = "" }, "content/poc-vs-mvp2.yaml", "thesis is required"},
+		{"image with alt", func(n *Note) { n.Image, n.ImageAlt = "/images/a.png", "A diagram" }, "content/poc-vs-mvp2.yaml", ""},
+		{"image without alt", func(n *Note) { n.Image = "/images/a.png" }, "content/poc-vs-mvp2.yaml", "image_alt is required"},
+		{"filename mismatch", func(*Note) {}, "content/poc.yaml", `expected "poc-vs-mvp2.yaml"`},
+		{"two-level slug", func(n *Note) { n.Slug = "go/concurrency" }, "content/go/concurrency.yaml", ""},
+		{"two-level slug in nested directory", func(n *Note) { n.Slug = "go/concurrency" }, "content/languages/go/concurrency.md", ""},
+		{"two-level slug in wrong directory", func(n *Note) { n.Slug = "go/concurrency" }, "content/rust/concurrency.yaml", `expected "go/concurrency.yaml"`},
+		{"leading slash", func(n *Note) { n.Slug = "/go" }, "content/go.yaml", "with / between segments"},
+		{"trailing slash", func(n *Note) { n.Slug = "go/" }, "content/go.yaml", "with / between segments"},
+		{"empty segment", func(n *Note) { n.Slug = "go//concurrency" }, "content/go/concurrency.yaml", "with / between segments"},
+		{"invalid id", func(n *Note) { n.ID = "not an id" }, "content/poc-vs-mvp2.yaml", `id "not an id" should only contain`},
+		{"title at limit", func(n *Note) { n.Title = strings.Repeat("é", 80) }, "content/poc-vs-mvp2.yaml", ""},
+		{"long title", func(n *Note) { n.Title = strings.Repeat("a", 81) }, "content/poc-vs-mvp2.yaml", "title is 81 characters, over the limit of 80"},
+		{"long thesis", func(n *Note) { n.Thesis = strings.Repeat("a", 301) }, "content/poc-vs-mvp2.yaml", "thesis is 301 characters"},
+		{"long bullet", func(n *Note) { n.Bullets = []string{"ok", strings.Repeat("a", 201)} }, "content/poc-vs-mvp2.yaml", "bullet 2 is 201 characters"},
+		{"description at limit", func(n *Note) { n.Description = strings.Repeat("a", 160) }, "content/poc-vs-mvp2.yaml", ""},
+		{"long description", func(n *Note) { n.Description = strings.Repeat("a", 161) }, "content/poc-vs-mvp2.yaml", "description is 161 characters, over the limit of 160"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			note := valid
+			tt.edit(&note)
+			err := validateNote(note, tt.filename, defaultFieldLimits)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestReadNotesDescription(t *testing.T) {
+	thesis := strings.Repeat("A thesis long enough to be cut. ", 8)
+	fsys := fstest.MapFS{
+		"content/written.yaml": {Data: []byte("slug: written\ntitle: Written\nthesis: On-page text.\ndescription: Search snippet text.\n")},
+		"content/derived.yaml": {Data: []byte("slug: derived\ntitle: Derived\nthesis: " + thesis + "\n")},
+	}
+
+	notes, err := readNotes(testConfig(t), fsys)
+	if err != nil {
+		t.Fatalf("readNotes failed: %v", err)
+	}
+	descriptions := make(map[string]string)
+	for _, note := range notes {
+		descriptions[note.Slug] = note.Description
+	}
+
+	if got := descriptions["written"]; got != "Search snippet text." {
+		t.Errorf("expected the written description, got %q", got)
+	}
+	if got, want := descriptions["derived"], summarize(thesis, maxDescriptionLength); got != want {
+		t.Errorf("expected the description to fall back to the truncated thesis %q, got %q", want, got)
+	}
+}
+
+func TestReadNotesDateLayout(t *testing.T) {
+	fsys := fstest.MapFS{
+		"content/custom.yaml": {Data: []byte("slug: custom\ntitle: Custom\nthesis: Written dates.\ndate: Mar 9, 2024\nupdated: Apr 10, 2024\n")},
+		"content/iso.yaml":    {Data: []byte("slug: iso\ntitle: ISO\nthesis: Scaffolded dates.\ndate: 2024-05-01\n")},
+	}
+	cfg := testConfig(t)
+	cfg.DateLayout = "Jan 2, 2006"
+
+	notes, err := readNotes(cfg, fsys)
+	if err != nil {
+		t.Fatalf("readNotes failed: %v", err)
+	}
+	custom := notes[0]
+	if custom.Date != "2024-03-09" || custom.Updated != "2024-04-10" {
+		t.Errorf("expected dates stored as ISO, got %q and %q", custom.Date, custom.Updated)
+	}
+
+	if err := generateSitemap(cfg, notes, defaultSitemapMaxURLs); err != nil {
+		t.Fatalf("generateSitemap returned error: %v", err)
+	}
+	var sitemap Sitemap
+	readXMLFile(t, filepath.Join(cfg.OutputDir, "sitemap.xml"), &sitemap)
+	lastMods := make(map[string]string)
+	for _, url := range sitemap.URLs {
+		lastMods[url.Loc] = url.LastMod
+	}
+	if got := lastMods["https://notes.example.com/custom/"]; got != "2024-04-10" {
+		t.Errorf("expected an ISO lastmod, got %q", got)
+	}
+	if got := lastMods["https://notes.example.com/iso/"]; got != "2024-05-01" {
+		t.Errorf("expected ISO dates to still parse, got %q", got)
+	}
+
+	fsys["content/bad.yaml"] = &fstest.MapFile{Data: []byte("slug: bad\ntitle: Bad\nthesis: Wrong layout.\ndate: 09/03/2024\n")}
+	_, err = readNotes(cfg, fsys)
+	if err == nil || !strings.Contains(err.Error(), "content/bad.yaml") || !strings.Contains(err.Error(), `"Jan 2, 2006"`) {
+		t.Errorf("expected an error naming the file and layout, got %v", err)
+	}
+}
+
+func TestReadNotesRejectsInvalidNote(t *testing.T) {
+	fsys := fstest.MapFS{
+		"content/renamed.yaml": {Data: []byte("slug: original\ntitle: Original\nthesis: Moved files keep their slug.\n")},
+	}
+
+	_, err := readNotes(testConfig(t), fsys)
+	if err == nil || !strings.Contains(err.Error(), "content/renamed.yaml") {
+		t.Errorf("expected an error naming the mismatched file, got %v", err)
+	}
+}
+
+func TestReadNotesMarkdownFrontMatter(t *testing.T) {
+	fsys := fstest.MapFS{
+		"content/markdown-note.md": {Data: []byte(`---
+slug: markdown-note
+title: Markdown Note
+thesis: Written with front matter.
+tags: [format]
+---
+
+The body is **Markdown**.
+
+- First
+- Second
+`)},
+		"content/yaml-note.yaml": {Data: []byte("slug: yaml-note\ntitle: YAML Note\nthesis: Still works.\n")},
+		"content/README.md":      {Data: []byte("# Content\n\nNot a note.\n")},
+	}
+
+	notes, err := readNotes(testConfig(t), fsys)
+	if err != nil {
+		t.Fatalf("readNotes failed: %v", err)
+	}
+	if len(notes) != 2 {
+		t.Fatalf("expected 2 notes, got %d", len(notes))
+	}
+
+	var note Note
+	for _, n := range notes {
+		if n.Slug == "markdown-note" {
+			note = n
+		}
+	}
+	if note.Title != "Markdown Note" || len(note.Tags) != 1 {
+		t.Errorf("expected front matter to be decoded, got %+v", note)
+	}
+	if !strings.HasPrefix(note.Body, "The body is **Markdown**.") {
+		t.Errorf("expected the body after the front matter, got %q", note.Body)
+	}
+	for _, want := range []string{"<strong>Markdown</strong>", "<li>Second</li>"} {
+		if !strings.Contains(string(note.BodyHTML), want) {
+			t.Errorf("rendered body %q does not contain %q", note.BodyHTML, want)
+		}
+	}
+}
+
+func TestSplitFrontMatter(t *testing.T) {
+	frontMatter, body, err := splitFrontMatter([]byte("---\ntitle: A\n---\nBody\n"))
+	if err != nil || string(frontMatter) != "title: A\n" || string(body) != "Body\n" {
+		t.Errorf("splitFrontMatter = (%q, %q, %v)", frontMatter, body, err)
+	}
+
+	if _, _, err := splitFrontMatter([]byte("# Heading\n")); !errors.Is(err, errNoFrontMatter) {
+		t.Errorf("expected errNoFrontMatter without front matter, got %v", err)
+	}
+	if _, _, err := splitFrontMatter([]byte("---\ntitle: A\n")); err == nil || errors.Is(err, errNoFrontMatter) {
+		t.Errorf("expected an error for unclosed front matter, got %v", err)
+	}
+}
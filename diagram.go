@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"strings"
+)
+
+// Supported values for the diagram_type field
+const (
+	diagramImage    = "image"
+	diagramMermaid  = "mermaid"
+	diagramPlantUML = "plantuml"
+	diagramText     = "text"
+)
+
+// plantUMLServer renders PlantUML diagrams encoded into the URL path
+const plantUMLServer = "https://www.plantuml.com/plantuml/svg/"
+
+// mermaidKeywords are the diagram declarations that identify Mermaid syntax
+var mermaidKeywords = []string{
+	"graph", "flowchart", "sequenceDiagram", "classDiagram", "stateDiagram",
+	"stateDiagram-v2", "erDiagram", "journey", "gantt", "pie", "mindmap",
+	"timeline", "gitGraph",
+}
+
+// resolveDiagramType returns the diagram type for note. Without an explicit
+// diagram_type, Mermaid syntax is detected and anything else is treated as an
+// image path, which preserves the rendering of existing notes.
+func resolveDiagramType(note Note) (string, error) {
+	switch note.DiagramType {
+	case diagramImage, diagramMermaid, diagramPlantUML, diagramText:
+		return note.DiagramType, nil
+	case "":
+		if isMermaid(note.Diagram) {
+			return diagramMermaid, nil
+		}
+		return diagramImage, nil
+	default:
+		return "", fmt.Errorf("unknown diagram_type %q, should be one of image, mermaid, plantuml, or text", note.DiagramType)
+	}
+}
+
+// isMermaid reports whether s begins with a Mermaid diagram declaration
+func isMermaid(s string) bool {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return false
+	}
+	for _, keyword := range mermaidKeywords {
+		if fields[0] == keyword {
+			return true
+		}
+	}
+	return false
+}
+
+// plantUMLURL returns a URL that renders source on the PlantUML server
+func plantUMLURL(source string) (string, error) {
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err := w.Write([]byte(source)); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return plantUMLServer + encodePlantUML(buf.Bytes()), nil
+}
+
+// encodePlantUML encodes data with the base64 variant used by PlantUML
+func encodePlantUML(data []byte) string {
+	const alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz-_"
+
+	var sb strings.Builder
+	for i := 0; i < len(data); i += 3 {
+		var b [3]byte
+		copy(b[:], data[i:])
+		sb.WriteByte(alphabet[b[0]>>2])
+		sb.WriteByte(alphabet[(b[0]&0x3)<<4|b[1]>>4])
+		sb.WriteByte(alphabet[(b[1]&0xF)<<2|b[2]>>6])
+		sb.WriteByte(alphabet[b[2]&0x3F])
+	}
+	return sb.String()
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolveDiagramType(t *testing.T) {
+	tests := []struct {
+		name string
+		note Note
+		want string
+	}{
+		{"image default", Note{Diagram: "/images/flow.png"}, diagramImage},
+		{"mermaid detected", Note{Diagram: "graph TD\n  A --> B"}, diagramMermaid},
+		{"explicit text", Note{Diagram: "graph TD", DiagramType: "text"}, diagramText},
+		{"explicit plantuml", Note{Diagram: "@startuml\n@enduml", DiagramType: "plantuml"}, diagramPlantUML},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveDiagramType(tt.note)
+			if err != nil {
+				t.Fatalf("resolveDiagramType returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("resolveDiagramType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := resolveDiagramType(Note{DiagramType: "visio"}); err == nil {
+		t.Error("expected an error for an unknown diagram type")
+	}
+}
+
+func TestPlantUMLURL(t *testing.T) {
+	url, err := plantUMLURL("@startuml\nAlice -> Bob\n@enduml")
+	if err != nil {
+		t.Fatalf("plantUMLURL returned error: %v", err)
+	}
+
+	encoded := strings.TrimPrefix(url, plantUMLServer)
+	if encoded == url || encoded == "" {
+		t.Fatalf("expected URL under %s, got %q", plantUMLServer, url)
+	}
+	for _, ch := range encoded {
+		if !strings.ContainsRune("0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz-_", ch) {
+			t.Errorf("encoded diagram contains unexpected character %q", ch)
+		}
+	}
+}
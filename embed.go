@@ -0,0 +1,40 @@
+package main
+
+import (
+	"html/template"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+// defaultEmbedHosts are the hosts iframes may load from when embed_hosts is
+// not configured
+var defaultEmbedHosts = []string{"www.youtube-nocookie.com", "www.youtube.com", "player.vimeo.com"}
+
+// newEmbedPolicy returns the policy for a note's raw html field: the block
+// elements allowed in Markdown plus iframes whose src is an https URL on one
+// of hosts
+func newEmbedPolicy(hosts []string) *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	if len(hosts) == 0 {
+		return p
+	}
+
+	quoted := make([]string, len(hosts))
+	for i, host := range hosts {
+		quoted[i] = regexp.QuoteMeta(host)
+	}
+	src := regexp.MustCompile(`^https://(` + strings.Join(quoted, "|") + `)/`)
+
+	p.AllowAttrs("src").Matching(src).OnElements("iframe")
+	p.AllowAttrs("width", "height").Matching(bluemonday.Number).OnElements("iframe")
+	p.AllowAttrs("title", "allow", "loading").OnElements("iframe")
+	p.AllowAttrs("allowfullscreen").Matching(regexp.MustCompile(`^(allowfullscreen|true|)$`)).OnElements("iframe")
+	return p
+}
+
+// renderEmbedHTML sanitizes a note's raw html field with policy
+func renderEmbedHTML(s string, policy *bluemonday.Policy) template.HTML {
+	return template.HTML(strings.TrimSpace(policy.Sanitize(s)))
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderEmbedHTML(t *testing.T) {
+	policy := newEmbedPolicy([]string{"www.youtube-nocookie.com"})
+
+	got := string(renderEmbedHTML(`<div class="widget"><script>alert(1)</script>`+
+		`<iframe src="https://www.youtube-nocookie.com/embed/abc" width="560" height="315" allowfullscreen></iframe></div>`, policy))
+	if strings.Contains(got, "<script") || strings.Contains(got, "alert") {
+		t.Errorf("expected scripts to be stripped, got %q", got)
+	}
+	if !strings.Contains(got, `<iframe src="https://www.youtube-nocookie.com/embed/abc" width="560" height="315" allowfullscreen`) {
+		t.Errorf("expected the allowed iframe to be kept, got %q", got)
+	}
+
+	for _, src := range []string{
+		"https://evil.example.com/embed/abc",
+		"http://www.youtube-nocookie.com/embed/abc",
+		"https://www.youtube-nocookie.com.evil.example.com/",
+		"javascript:alert(1)",
+	} {
+		got := string(renderEmbedHTML(`<iframe src="`+src+`"></iframe>`, policy))
+		if strings.Contains(got, "src=") {
+			t.Errorf("expected iframe src %q to be removed, got %q", src, got)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import "strings"
+
+// emojiShortcodes maps common GitHub-style shortcodes to their emoji
+var emojiShortcodes = map[string]string{
+	"+1":                       "👍",
+	"-1":                       "👎",
+	"100":                      "💯",
+	"alarm_clock":              "⏰",
+	"arrow_down":               "⬇️",
+	"arrow_left":               "⬅️",
+	"arrow_right":              "➡️",
+	"arrow_up":                 "⬆️",
+	"beer":                     "🍺",
+	"bell":                     "🔔",
+	"book":                     "📖",
+	"books":                    "📚",
+	"boom":                     "💥",
+	"brain":                    "🧠",
+	"bug":                      "🐛",
+	"bulb":                     "💡",
+	"calendar":                 "📆",
+	"chart_with_upwards_trend": "📈",
+	"check":                    "✔️",
+	"clap":                     "👏",
+	"clipboard":                "📋",
+	"clock":                    "🕐",
+	"cloud":                    "☁️",
+	"coffee":                   "☕",
+	"computer":                 "💻",
+	"confused":                 "😕",
+	"construction":             "🚧",
+	"cry":                      "😢",
+	"dart":                     "🎯",
+	"email":                    "📧",
+	"eyes":                     "👀",
+	"fire":                     "🔥",
+	"gear":                     "⚙️",
+	"gift":                     "🎁",
+	"globe_with_meridians":     "🌐",
+	"grin":                     "😁",
+	"hammer":                   "🔨",
+	"hammer_and_wrench":        "🛠️",
+	"heart":                    "❤️",
+	"heavy_check_mark":         "✔️",
+	"hourglass":                "⌛",
+	"hugs":                     "🤗",
+	"information_source":       "ℹ️",
+	"joy":                      "😂",
+	"key":                      "🔑",
+	"laughing":                 "😆",
+	"link":                     "🔗",
+	"lock":                     "🔒",
+	"mag":                      "🔍",
+	"memo":                     "📝",
+	"money_with_wings":         "💸",
+	"muscle":                   "💪",
+	"no_entry":                 "⛔",
+	"ok_hand":                  "👌",
+	"package":                  "📦",
+	"pencil2":                  "✏️",
+	"point_right":              "👉",
+	"pray":                     "🙏",
+	"pushpin":                  "📌",
+	"question":                 "❓",
+	"raised_hands":             "🙌",
+	"recycle":                  "♻️",
+	"rocket":                   "🚀",
+	"rotating_light":           "🚨",
+	"scream":                   "😱",
+	"see_no_evil":              "🙈",
+	"shield":                   "🛡️",
+	"shrug":                    "🤷",
+	"slightly_smiling_face":    "🙂",
+	"smile":                    "😄",
+	"smiley":                   "😃",
+	"snail":                    "🐌",
+	"sparkles":                 "✨",
+	"star":                     "⭐",
+	"stop_sign":                "🛑",
+	"sunglasses":               "😎",
+	"tada":                     "🎉",
+	"thinking":                 "🤔",
+	"thumbsdown":               "👎",
+	"thumbsup":                 "👍",
+	"trophy":                   "🏆",
+	"turtle":                   "🐢",
+	"warning":                  "⚠️",
+	"wave":                     "👋",
+	"white_check_mark":         "✅",
+	"wink":                     "😉",
+	"wrench":                   "🔧",
+	"x":                        "❌",
+	"zap":                      "⚡",
+}
+
+// expandEmoji replaces each known :shortcode: in s with its emoji. Unknown
+// shortcodes and text inside `code` spans are left unchanged.
+func expandEmoji(s string) string {
+	if !strings.Contains(s, ":") {
+		return s
+	}
+	parts := strings.Split(s, "`")
+	for i := 0; i < len(parts); i += 2 {
+		parts[i] = expandShortcodes(parts[i])
+	}
+	return strings.Join(parts, "`")
+}
+
+// expandShortcodes replaces each known :shortcode: in s with its emoji. A
+// colon that doesn't start a known shortcode is kept as text, so in
+// "at 10:30 :tada:" only the shortcode is replaced.
+func expandShortcodes(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == ':' {
+			if end := strings.IndexByte(s[i+1:], ':'); end > 0 {
+				if emoji, ok := emojiShortcodes[s[i+1:i+1+end]]; ok {
+					b.WriteString(emoji)
+					i += end + 1
+					continue
+				}
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// expandNoteEmoji expands emoji shortcodes in the title, thesis, and bullets
+// of note
+func expandNoteEmoji(note *Note) {
+	note.Title = expandEmoji(note.Title)
+	note.Thesis = expandEmoji(note.Thesis)
+	for i, bullet := range note.Bullets {
+		note.Bullets[i] = expandEmoji(bullet)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExpandEmoji(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{":tada: Shipped", "🎉 Shipped"},
+		{"Launch :rocket::sparkles:", "Launch 🚀✨"},
+		{"At 10:30 :tada:", "At 10:30 🎉"},
+		{"Unknown :not_an_emoji: stays", "Unknown :not_an_emoji: stays"},
+		{"Code `:tada:` is literal, :tada: is not", "Code `:tada:` is literal, 🎉 is not"},
+		{"No shortcodes here", "No shortcodes here"},
+	}
+	for _, tt := range tests {
+		if got := expandEmoji(tt.input); got != tt.want {
+			t.Errorf("expandEmoji(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestPrepareNoteExpandsEmoji(t *testing.T) {
+	cfg := testConfig(t)
+	note := Note{
+		Slug:    "party",
+		Title:   "Release :tada:",
+		Thesis:  "Ship it :rocket:",
+		Bullets: []string{"Celebrate :tada:", "Keep `:tada:` in code"},
+		Example: "Examples keep :tada:",
+		Source:  "content/party.yaml",
+	}
+	if err := prepareNote(cfg, &note, newEmbedPolicy(nil)); err != nil {
+		t.Fatalf("prepareNote returned error: %v", err)
+	}
+
+	if note.Title != "Release 🎉" {
+		t.Errorf("title = %q, want the emoji", note.Title)
+	}
+	if string(note.ThesisHTML) != "Ship it 🚀" {
+		t.Errorf("rendered thesis = %q, want the emoji", note.ThesisHTML)
+	}
+	if string(note.BulletsHTML[0]) != "Celebrate 🎉" || !strings.Contains(string(note.BulletsHTML[1]), "<code>:tada:</code>") {
+		t.Errorf("unexpected rendered bullets %q", note.BulletsHTML)
+	}
+	if !strings.Contains(string(note.ExampleHTML), ":tada:") {
+		t.Errorf("shortcodes in the example should not be expanded, got %q", note.ExampleHTML)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// generateNotesJSON writes notes.json, every published note with all of its
+// content fields after defaults are applied, for building other frontends on
+// the same data. Fields are written in struct order and map keys sorted, so
+// the output is stable between builds.
+func generateNotesJSON(outDir string, notes []Note) error {
+	published := publishedNotes(notes)
+	data, err := json.MarshalIndent(published, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(outDir, "notes.json"), data, 0644)
+}
+
+// apiDir is the output directory of the per-note JSON API
+const apiDir = "api"
+
+// APINote is a note as served by the JSON API, with the slugs of the notes
+// it links to on its page
+type APINote struct {
+	Note
+	URL     string   `json:"url"`
+	Related []string `json:"related"`
+	Prev    string   `json:"prev,omitempty"`
+	Next    string   `json:"next,omitempty"`
+}
+
+// APIIndexEntry is a note in the JSON API index, with the fields needed to
+// list it and the path of its full data
+type APIIndexEntry struct {
+	Slug    string   `json:"slug"`
+	Title   string   `json:"title"`
+	Excerpt string   `json:"excerpt"`
+	Date    string   `json:"date,omitempty"`
+	Tags    []string `json:"tags"`
+	URL     string   `json:"url"`
+	API     string   `json:"api"`
+}
+
+// apiPath returns the root-relative path of the JSON API file for slug
+func apiPath(slug string) string {
+	return "/" + apiDir + "/" + slug + ".json"
+}
+
+// generateNoteAPI writes api/<slug>.json for every published note, with its
+// related notes and neighbors in sort order, and api/index.json listing them
+// all. It returns the number of notes written.
+func generateNoteAPI(cfg Config, notes []Note) (int, error) {
+	published := publishedNotes(notes)
+	index := make([]APIIndexEntry, 0, len(published))
+	for i, note := range published {
+		entry := APINote{
+			Note:    note,
+			URL:     noteCanonicalURL(cfg, note.Slug),
+			Related: []string{},
+		}
+		for _, related := range relatedNotes(note, published, relatedNoteCount) {
+			entry.Related = append(entry.Related, related.Slug)
+		}
+		if i > 0 {
+			entry.Prev = published[i-1].Slug
+		}
+		if i < len(published)-1 {
+			entry.Next = published[i+1].Slug
+		}
+
+		path := filepath.Join(cfg.OutputDir, filepath.FromSlash(apiPath(note.Slug)))
+		if err := writeJSONFile(path, entry); err != nil {
+			return 0, fmt.Errorf("writing %s: %w", note.Slug, err)
+		}
+
+		tags := note.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		index = append(index, APIIndexEntry{
+			Slug:    note.Slug,
+			Title:   note.Title,
+			Excerpt: note.Excerpt,
+			Date:    note.Date,
+			Tags:    tags,
+			URL:     entry.URL,
+			API:     cfg.BasePath + apiPath(note.Slug),
+		})
+	}
+	if err := writeJSONFile(filepath.Join(cfg.OutputDir, apiDir, "index.json"), index); err != nil {
+		return 0, err
+	}
+	return len(published), nil
+}
+
+// writeJSONFile writes v to path as indented JSON, creating its directory
+func writeJSONFile(path string, v any) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGenerateNotesJSONRoundTrip(t *testing.T) {
+	outDir := t.TempDir()
+	notes := []Note{
+		{
+			Slug:         "a",
+			Title:        "A",
+			Thesis:       "First.",
+			Bullets:      []string{"one", "two"},
+			Links:        []Link{{Label: "Docs", URL: "https://example.com"}},
+			Tags:         []string{"go"},
+			Theme:        "blue",
+			Date:         "2024-01-02",
+			Lang:         "en",
+			Translations: map[string]string{"de": "a-de"},
+		},
+		{Slug: "b", Title: "B", Thesis: "Second.", Featured: true, Order: 2},
+	}
+
+	if err := generateNotesJSON(outDir, append(notes, Note{Slug: "draft", Draft: true})); err != nil {
+		t.Fatalf("generateNotesJSON returned error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(outDir, "notes.json"))
+	if err != nil {
+		t.Fatalf("Failed to read notes.json: %v", err)
+	}
+
+	var got []Note
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Failed to parse notes.json: %v", err)
+	}
+	if !reflect.DeepEqual(got, notes) {
+		t.Errorf("round trip mismatch:\ngot  %+v\nwant %+v", got, notes)
+	}
+}
+
+func TestGenerateNoteAPI(t *testing.T) {
+	cfg := testConfig(t)
+	notes := []Note{
+		{Slug: "first", Title: "First", Thesis: "One.", Excerpt: "One.", Tags: []string{"go"}},
+		{Slug: "go/nested", Title: "Nested", Thesis: "Two.", Tags: []string{"go"}},
+		{Slug: "last", Title: "Last", Thesis: "Three."},
+		{Slug: "draft", Title: "Draft", Draft: true},
+	}
+
+	written, err := generateNoteAPI(cfg, notes)
+	if err != nil {
+		t.Fatalf("generateNoteAPI returned error: %v", err)
+	}
+	if written != 3 {
+		t.Errorf("expected 3 notes written, got %d", written)
+	}
+
+	entries := make(map[string]APINote)
+	for _, note := range publishedNotes(notes) {
+		data, err := os.ReadFile(filepath.Join(cfg.OutputDir, "api", filepath.FromSlash(note.Slug)+".json"))
+		if err != nil {
+			t.Errorf("expected a JSON file for %s: %v", note.Slug, err)
+			continue
+		}
+		var entry APINote
+		if err := json.Unmarshal(data, &entry); err != nil {
+			t.Errorf("JSON for %s does not parse: %v", note.Slug, err)
+			continue
+		}
+		if entry.Slug != note.Slug || entry.Title != note.Title {
+			t.Errorf("JSON for %s has slug %q and title %q", note.Slug, entry.Slug, entry.Title)
+		}
+		entries[note.Slug] = entry
+	}
+	if _, err := os.Stat(filepath.Join(cfg.OutputDir, "api", "draft.json")); err == nil {
+		t.Error("drafts should not be written to the API")
+	}
+
+	nested := entries["go/nested"]
+	if nested.Prev != "first" || nested.Next != "last" {
+		t.Errorf("expected neighbors first and last, got %q and %q", nested.Prev, nested.Next)
+	}
+	if !reflect.DeepEqual(nested.Related, []string{"first"}) {
+		t.Errorf("expected related slugs [first], got %v", nested.Related)
+	}
+	if nested.URL != "https://notes.example.com/go/nested/" {
+		t.Errorf("unexpected url %q", nested.URL)
+	}
+
+	data, err := os.ReadFile(filepath.Join(cfg.OutputDir, "api", "index.json"))
+	if err != nil {
+		t.Fatalf("Failed to read api/index.json: %v", err)
+	}
+	var index []APIIndexEntry
+	if err := json.Unmarshal(data, &index); err != nil {
+		t.Fatalf("api/index.json does not parse: %v", err)
+	}
+	if len(index) != 3 || index[1].API != "/api/go/nested.json" {
+		t.Errorf("unexpected index %+v", index)
+	}
+	if strings.Contains(string(data), "thesis") {
+		t.Error("the index should only list summary fields")
+	}
+}
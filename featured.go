@@ -0,0 +1,14 @@
+package main
+
+// featuredNotes returns the notes marked featured, ordered by their order
+// field and then by slug like the full list
+func featuredNotes(notes []Note) []Note {
+	var featured []Note
+	for _, note := range notes {
+		if note.Featured {
+			featured = append(featured, note)
+		}
+	}
+	sortNotes(featured)
+	return featured
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFeaturedNotes(t *testing.T) {
+	notes := []Note{
+		{Slug: "alpha", Featured: true},
+		{Slug: "beta"},
+		{Slug: "gamma", Featured: true, Order: 1},
+	}
+	sortNotes(notes)
+
+	var got []string
+	for _, note := range featuredNotes(notes) {
+		got = append(got, note.Slug)
+	}
+	if want := []string{"gamma", "alpha"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("featuredNotes() = %v, want %v", got, want)
+	}
+
+	cfg := testConfig(t)
+	tmpl, err := parseTemplate(siteFS, templateFuncs(cfg, nil), "templates/index.html", "templates/footer.html")
+	if err != nil {
+		t.Fatalf("Failed to parse index template: %v", err)
+	}
+	if err := generateIndex(cfg, tmpl, notes); err != nil {
+		t.Fatalf("generateIndex returned error: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(cfg.OutputDir, "index.html"))
+	if err != nil {
+		t.Fatalf("Failed to read index page: %v", err)
+	}
+	page := string(content)
+	featured, all, ok := strings.Cut(page, `id="notesGrid"`)
+	if !ok || !strings.Contains(featured, `class="featured-notes"`) {
+		t.Fatal("expected a featured section above the full list")
+	}
+	for _, slug := range []string{"alpha", "gamma"} {
+		if !strings.Contains(featured, `href="/`+slug+`/"`) {
+			t.Errorf("featured section should list %s", slug)
+		}
+		if !strings.Contains(all, `href="/`+slug+`/"`) {
+			t.Errorf("full list should still list %s", slug)
+		}
+	}
+	if strings.Contains(featured, `href="/beta/"`) {
+		t.Error("featured section should not list notes that are not featured")
+	}
+}
@@ -0,0 +1,386 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RSS represents the root RSS 2.0 element
+type RSS struct {
+	XMLName    xml.Name `xml:"rss"`
+	Version    string   `xml:"version,attr"`
+	XMLNSDC    string   `xml:"xmlns:dc,attr"`
+	XMLNSMedia string   `xml:"xmlns:media,attr"`
+	Channel    Channel  `xml:"channel"`
+}
+
+// Channel represents the RSS channel containing the feed items
+type Channel struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description"`
+	Items       []Item `xml:"item"`
+}
+
+// Item represents a single note in the RSS feed
+type Item struct {
+	Title       string     `xml:"title"`
+	Link        string     `xml:"link"`
+	GUID        GUID       `xml:"guid"`
+	Description string     `xml:"description"`
+	PubDate     string     `xml:"pubDate,omitempty"`
+	Creator     string     `xml:"dc:creator,omitempty"`
+	Enclosure   *Enclosure `xml:"enclosure,omitempty"`
+	Media       *Media     `xml:"media:content,omitempty"`
+}
+
+// GUID identifies an RSS item. IsPermaLink is "false" when the GUID is not
+// the item's URL.
+type GUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink string `xml:"isPermaLink,attr,omitempty"`
+}
+
+// Enclosure represents media attached to an RSS item. The length is reported
+// as 0 because the size of remote images is not known at build time.
+type Enclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int    `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+// Media describes an image attached to an RSS item using Media RSS, which
+// unlike an enclosure can carry the image's alt text
+type Media struct {
+	URL         string `xml:"url,attr"`
+	Medium      string `xml:"medium,attr"`
+	Description string `xml:"media:description"`
+}
+
+// AtomFeed represents the root Atom 1.0 feed element
+type AtomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	XMLNS   string      `xml:"xmlns,attr"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []AtomLink  `xml:"link"`
+	Author  AtomPerson  `xml:"author"`
+	Entries []AtomEntry `xml:"entry"`
+}
+
+// AtomLink represents an Atom link element
+type AtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+// AtomPerson represents an Atom person construct such as author
+type AtomPerson struct {
+	Name string `xml:"name"`
+}
+
+// AtomEntry represents a single note in the Atom feed
+type AtomEntry struct {
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Link    AtomLink    `xml:"link"`
+	Summary string      `xml:"summary"`
+	Author  *AtomPerson `xml:"author,omitempty"`
+}
+
+// JSONFeed represents a JSON Feed 1.1 document
+type JSONFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Description string         `json:"description,omitempty"`
+	Items       []JSONFeedItem `json:"items"`
+}
+
+// JSONFeedItem represents a single note in the JSON Feed
+type JSONFeedItem struct {
+	ID            string `json:"id"`
+	URL           string `json:"url"`
+	Title         string `json:"title"`
+	ContentText   string `json:"content_text"`
+	Summary       string `json:"summary,omitempty"`
+	DatePublished string `json:"date_published,omitempty"`
+	DateModified  string `json:"date_modified,omitempty"`
+}
+
+func generateRSS(cfg Config, notes []Note) error {
+	channel := Channel{
+		Title:       cfg.SiteTitle,
+		Link:        cfg.BaseURL + "/",
+		Description: cfg.Description,
+	}
+	return writeRSSFeed(cfg, filepath.Join(cfg.OutputDir, "rss.xml"), channel, notes)
+}
+
+// writeRSSFeed writes channel to path with an item for each indexable note
+// in notes, newest first
+func writeRSSFeed(cfg Config, path string, channel Channel, notes []Note) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	indexable := newestFirst(indexableNotes(notes))
+	channel.Items = make([]Item, 0, len(indexable))
+	for _, note := range indexable {
+		item, err := rssItem(cfg, note)
+		if err != nil {
+			return err
+		}
+		channel.Items = append(channel.Items, item)
+	}
+
+	rss := RSS{
+		Version:    "2.0",
+		XMLNSDC:    "http://purl.org/dc/elements/1.1/",
+		XMLNSMedia: "http://search.yahoo.com/mrss/",
+		Channel:    channel,
+	}
+
+	// Write XML header
+	if _, err := f.WriteString(xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(f)
+	encoder.Indent("", "  ")
+	return encoder.Encode(rss)
+}
+
+// rssItem builds the RSS item for note
+func rssItem(cfg Config, note Note) (Item, error) {
+	link := noteCanonicalURL(cfg, note.Slug)
+	item := Item{
+		Title:       note.Title,
+		Link:        link,
+		GUID:        GUID{Value: noteFeedID(cfg, note)},
+		Description: note.Description,
+		Creator:     note.Author,
+	}
+	if note.ID != "" {
+		item.GUID.IsPermaLink = "false"
+	}
+	if note.Date != "" {
+		date, err := time.Parse(dateLayout, note.Date)
+		if err != nil {
+			return item, fmt.Errorf("parsing date for %s: %w", note.Slug, err)
+		}
+		item.PubDate = date.Format(time.RFC1123Z)
+	}
+	if note.Image != "" {
+		item.Enclosure = &Enclosure{
+			URL:  absoluteURL(cfg.BaseURL, note.Image),
+			Type: imageType(note.Image),
+		}
+		item.Media = &Media{
+			URL:         item.Enclosure.URL,
+			Medium:      "image",
+			Description: note.ImageAlt,
+		}
+	}
+	return item, nil
+}
+
+func generateAtom(cfg Config, notes []Note) error {
+	baseURL := cfg.BaseURL
+
+	f, err := os.Create(filepath.Join(cfg.OutputDir, "atom.xml"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	buildTime := cfg.BuildTime
+	feed := AtomFeed{
+		XMLNS: "http://www.w3.org/2005/Atom",
+		Title: cfg.SiteTitle,
+		ID:    baseURL + "/",
+		Links: []AtomLink{
+			{Href: baseURL + "/"},
+			{Href: baseURL + "/atom.xml", Rel: "self"},
+		},
+		Author: AtomPerson{Name: cmp.Or(cfg.Author, cfg.SiteTitle)},
+	}
+
+	// The feed is updated as of the most recently changed note
+	var latest time.Time
+	for _, note := range newestFirst(indexableNotes(notes)) {
+		updated := buildTime
+		if modified := lastModified(note); modified != "" {
+			date, err := time.Parse(dateLayout, modified)
+			if err != nil {
+				return fmt.Errorf("parsing date for %s: %w", note.Slug, err)
+			}
+			updated = date
+			if date.After(latest) {
+				latest = date
+			}
+		}
+
+		link := noteCanonicalURL(cfg, note.Slug)
+		entry := AtomEntry{
+			Title:   note.Title,
+			ID:      noteFeedID(cfg, note),
+			Updated: updated.Format(time.RFC3339),
+			Link:    AtomLink{Href: link},
+			Summary: note.Description,
+		}
+		if note.Author != "" {
+			entry.Author = &AtomPerson{Name: note.Author}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	if latest.IsZero() {
+		latest = buildTime
+	}
+	feed.Updated = latest.Format(time.RFC3339)
+
+	// Write XML header
+	if _, err := f.WriteString(xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(f)
+	encoder.Indent("", "  ")
+	return encoder.Encode(feed)
+}
+
+func generateJSONFeed(cfg Config, notes []Note) error {
+	baseURL := cfg.BaseURL
+	var err error
+
+	feed := JSONFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       cfg.SiteTitle,
+		HomePageURL: baseURL + "/",
+		FeedURL:     baseURL + "/feed.json",
+		Description: cfg.Description,
+		Items:       []JSONFeedItem{},
+	}
+
+	for _, note := range newestFirst(indexableNotes(notes)) {
+		link := noteCanonicalURL(cfg, note.Slug)
+		item := JSONFeedItem{
+			ID:          noteFeedID(cfg, note),
+			URL:         link,
+			Title:       note.Title,
+			ContentText: strings.Join(append([]string{note.Thesis}, note.Bullets...), "\n\n"),
+			Summary:     note.Description,
+		}
+		if note.Date != "" {
+			if item.DatePublished, err = formatFeedDate(note.Date); err != nil {
+				return fmt.Errorf("parsing date for %s: %w", note.Slug, err)
+			}
+		}
+		if note.Updated != "" {
+			if item.DateModified, err = formatFeedDate(note.Updated); err != nil {
+				return fmt.Errorf("parsing updated date for %s: %w", note.Slug, err)
+			}
+		}
+		feed.Items = append(feed.Items, item)
+	}
+
+	data, err := json.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(cfg.OutputDir, "feed.json"), data, 0644)
+}
+
+// OPML represents the root OPML 2.0 element listing the site's feeds
+type OPML struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    OPMLHead `xml:"head"`
+	Body    OPMLBody `xml:"body"`
+}
+
+// OPMLHead holds the OPML document metadata
+type OPMLHead struct {
+	Title       string `xml:"title"`
+	DateCreated string `xml:"dateCreated"`
+}
+
+// OPMLBody holds one outline per feed
+type OPMLBody struct {
+	Outlines []OPMLOutline `xml:"outline"`
+}
+
+// OPMLOutline represents a single feed subscription
+type OPMLOutline struct {
+	Type    string `xml:"type,attr"`
+	Text    string `xml:"text,attr"`
+	Title   string `xml:"title,attr"`
+	XMLURL  string `xml:"xmlUrl,attr"`
+	HTMLURL string `xml:"htmlUrl,attr"`
+}
+
+// generateOPML writes feeds.opml listing the RSS and Atom feeds so they can
+// be imported into a feed reader in one step
+func generateOPML(cfg Config) error {
+	baseURL := cfg.BaseURL
+	opml := OPML{
+		Version: "2.0",
+		Head: OPMLHead{
+			Title:       cfg.SiteTitle,
+			DateCreated: cfg.BuildTime.Format(time.RFC1123Z),
+		},
+		Body: OPMLBody{Outlines: []OPMLOutline{
+			{Type: "rss", Text: cfg.SiteTitle + " (RSS)", Title: cfg.SiteTitle, XMLURL: baseURL + "/rss.xml", HTMLURL: baseURL + "/"},
+			{Type: "rss", Text: cfg.SiteTitle + " (Atom)", Title: cfg.SiteTitle, XMLURL: baseURL + "/atom.xml", HTMLURL: baseURL + "/"},
+		}},
+	}
+	return writeXMLFile(filepath.Join(cfg.OutputDir, "feeds.opml"), opml)
+}
+
+// formatFeedDate converts a note date to the RFC 3339 format used by feeds
+func formatFeedDate(date string) (string, error) {
+	t, err := time.Parse(dateLayout, date)
+	if err != nil {
+		return "", err
+	}
+	return t.Format(time.RFC3339), nil
+}
+
+// imageType returns the MIME type of an image from its file extension
+func imageType(ref string) string {
+	if t := mime.TypeByExtension(path.Ext(ref)); t != "" {
+		return t
+	}
+	return "image/*"
+}
+
+// newestFirst returns a copy of notes ordered by date descending. Undated
+// notes are placed last and keep their existing relative order.
+func newestFirst(notes []Note) []Note {
+	sorted := make([]Note, len(notes))
+	copy(sorted, notes)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Date == "" || sorted[j].Date == "" {
+			return sorted[j].Date == "" && sorted[i].Date != ""
+		}
+		// ISO dates sort lexically
+		return sorted[i].Date > sorted[j].Date
+	})
+	return sorted
+}
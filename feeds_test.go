@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateJSONFeed(t *testing.T) {
+	cfg := testConfig(t)
+	outDir := cfg.OutputDir
+
+	notes := []Note{
+		{Slug: "older", Title: "Older", Thesis: "First.", Date: "2024-01-01"},
+		{Slug: "newer", Title: "Newer", Thesis: "Second.", Description: "Second note.", Date: "2024-02-01", Updated: "2024-03-01"},
+		{Slug: "draft", Title: "Draft", Thesis: "Hidden.", Draft: true},
+		{Slug: "unlisted", Title: "Unlisted", Thesis: "Not indexed.", NoIndex: true},
+	}
+	if err := generateJSONFeed(cfg, notes); err != nil {
+		t.Fatalf("generateJSONFeed returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outDir, "feed.json"))
+	if err != nil {
+		t.Fatalf("Failed to read feed.json: %v", err)
+	}
+
+	// Decode generically so required keys are checked by their spec names
+	var feed map[string]any
+	if err := json.Unmarshal(data, &feed); err != nil {
+		t.Fatalf("feed.json is not valid JSON: %v", err)
+	}
+	for _, key := range []string{"version", "title", "items"} {
+		if _, ok := feed[key]; !ok {
+			t.Errorf("feed is missing required key %q", key)
+		}
+	}
+	if feed["version"] != "https://jsonfeed.org/version/1.1" {
+		t.Errorf("unexpected version %v", feed["version"])
+	}
+
+	items, _ := feed["items"].([]any)
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items excluding the draft and noindex notes, got %d", len(items))
+	}
+	first, _ := items[0].(map[string]any)
+	if first["id"] != "https://notes.example.com/newer/" {
+		t.Errorf("expected newest item first with canonical id, got %v", first["id"])
+	}
+	if first["content_text"] == "" || first["summary"] != "Second note." || first["date_published"] != "2024-02-01T00:00:00Z" {
+		t.Errorf("unexpected first item: %v", first)
+	}
+}
+
+func TestGenerateOPML(t *testing.T) {
+	cfg := testConfig(t)
+	if err := generateOPML(cfg); err != nil {
+		t.Fatalf("generateOPML returned error: %v", err)
+	}
+
+	var opml OPML
+	readXMLFile(t, filepath.Join(cfg.OutputDir, "feeds.opml"), &opml)
+	if opml.Version != "2.0" || opml.Head.Title != cfg.SiteTitle {
+		t.Errorf("unexpected OPML head: version %q, title %q", opml.Version, opml.Head.Title)
+	}
+
+	var urls []string
+	for _, outline := range opml.Body.Outlines {
+		urls = append(urls, outline.XMLURL)
+	}
+	want := []string{"https://notes.example.com/rss.xml", "https://notes.example.com/atom.xml"}
+	if len(urls) != len(want) || urls[0] != want[0] || urls[1] != want[1] {
+		t.Errorf("OPML lists feeds %v, want %v", urls, want)
+	}
+}
+
+func TestGenerateRSSImageAlt(t *testing.T) {
+	cfg := testConfig(t)
+	notes := []Note{{Slug: "pictured", Title: "Pictured", Thesis: "Has an image.", Image: "/images/cover.png", ImageAlt: "A cover diagram"}}
+	if err := generateRSS(cfg, notes); err != nil {
+		t.Fatalf("generateRSS returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(cfg.OutputDir, "rss.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`xmlns:media="http://search.yahoo.com/mrss/"`,
+		`<media:content url="https://notes.example.com/images/cover.png" medium="image">`,
+		`<media:description>A cover diagram</media:description>`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("rss.xml does not contain %s:\n%s", want, data)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// footnoteRef matches a footnote reference such as [^1] or [^source] in
+// rendered note fields
+var footnoteRef = regexp.MustCompile(`\[\^([A-Za-z0-9_-]+)\]`)
+
+// Footnote is a footnote collected from a note, numbered in order of first
+// reference. ID is the anchor of the footnote itself and BackRefs are the
+// anchors of each reference to it, so readers can jump back.
+type Footnote struct {
+	Number   int
+	ID       string
+	BackRefs []string
+	HTML     template.HTML
+}
+
+// linkFootnotes replaces footnote references in the rendered thesis, bullets,
+// Markdown example, and body of note with links to its footnotes, and populates
+// note.FootnotesHTML. Footnotes are numbered across the whole note so their
+// IDs are unique on the page. It returns an error when a reference has no
+// footnote or a footnote is never referenced.
+func linkFootnotes(note *Note) error {
+	if len(note.Footnotes) == 0 && !hasFootnoteRefs(note) {
+		return nil
+	}
+
+	byLabel := make(map[string]*Footnote)
+	var order []string
+	var missing []string
+	link := func(html template.HTML) template.HTML {
+		return template.HTML(footnoteRef.ReplaceAllStringFunc(string(html), func(ref string) string {
+			label := footnoteRef.FindStringSubmatch(ref)[1]
+			if _, ok := note.Footnotes[label]; !ok {
+				missing = append(missing, label)
+				return ref
+			}
+			fn, ok := byLabel[label]
+			if !ok {
+				fn = &Footnote{Number: len(order) + 1}
+				fn.ID = fmt.Sprintf("fn-%d", fn.Number)
+				byLabel[label] = fn
+				order = append(order, label)
+			}
+			refID := fmt.Sprintf("fnref-%d", fn.Number)
+			if n := len(fn.BackRefs); n > 0 {
+				refID = fmt.Sprintf("%s-%d", refID, n+1)
+			}
+			fn.BackRefs = append(fn.BackRefs, refID)
+			return fmt.Sprintf(`<sup class="footnote-ref" id="%s"><a href="#%s" role="doc-noteref">%d</a></sup>`, refID, fn.ID, fn.Number)
+		}))
+	}
+
+	note.ThesisHTML = link(note.ThesisHTML)
+	for i := range note.BulletsHTML {
+		note.BulletsHTML[i] = link(note.BulletsHTML[i])
+	}
+	// Highlighted code examples are shown verbatim
+	if note.ExampleLang == "" {
+		note.ExampleHTML = link(note.ExampleHTML)
+	}
+	note.BodyHTML = link(note.BodyHTML)
+
+	if len(missing) > 0 {
+		return fmt.Errorf("footnote references without a footnote: %s", strings.Join(missing, ", "))
+	}
+	var unused []string
+	for label := range note.Footnotes {
+		if _, ok := byLabel[label]; !ok {
+			unused = append(unused, label)
+		}
+	}
+	if len(unused) > 0 {
+		sort.Strings(unused)
+		return fmt.Errorf("footnotes never referenced: %s", strings.Join(unused, ", "))
+	}
+
+	note.FootnotesHTML = make([]Footnote, 0, len(order))
+	for _, label := range order {
+		fn := byLabel[label]
+		html, err := renderInlineMarkdown(note.Footnotes[label])
+		if err != nil {
+			return err
+		}
+		fn.HTML = html
+		note.FootnotesHTML = append(note.FootnotesHTML, *fn)
+	}
+	return nil
+}
+
+// hasFootnoteRefs reports whether any source field of note references a footnote
+func hasFootnoteRefs(note *Note) bool {
+	if footnoteRef.MatchString(note.Thesis) || footnoteRef.MatchString(note.Body) || (note.ExampleLang == "" && footnoteRef.MatchString(note.Example)) {
+		return true
+	}
+	for _, bullet := range note.Bullets {
+		if footnoteRef.MatchString(bullet) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRenderNoteWithFootnotes(t *testing.T) {
+	note := Note{
+		Slug:    "footnoted",
+		Title:   "Footnoted",
+		Thesis:  "Caches are hard[^cache].",
+		Bullets: []string{"Naming is too[^naming]", "Still caches[^cache]"},
+		Footnotes: map[string]string{
+			"cache":  "See *Phil Karlton*.",
+			"naming": "Also Phil Karlton.",
+		},
+	}
+	if err := renderNoteMarkdown(&note); err != nil {
+		t.Fatalf("renderNoteMarkdown failed: %v", err)
+	}
+
+	if len(note.FootnotesHTML) != 2 {
+		t.Fatalf("expected 2 footnotes, got %d", len(note.FootnotesHTML))
+	}
+	first, second := note.FootnotesHTML[0], note.FootnotesHTML[1]
+	if first.ID != "fn-1" || len(first.BackRefs) != 2 || first.BackRefs[1] != "fnref-1-2" {
+		t.Errorf("unexpected first footnote %+v", first)
+	}
+	if second.ID != "fn-2" || len(second.BackRefs) != 1 || second.BackRefs[0] != "fnref-2" {
+		t.Errorf("unexpected second footnote %+v", second)
+	}
+	if !strings.Contains(string(first.HTML), "<em>Phil Karlton</em>") {
+		t.Errorf("expected footnote Markdown to be rendered, got %q", first.HTML)
+	}
+
+	tmpl, err := parseTemplate(siteFS, templateFuncs(Config{}, nil), "templates/note.html", "templates/footer.html")
+	if err != nil {
+		t.Fatalf("Failed to parse note template: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, NotePageData{Note: note}); err != nil {
+		t.Fatalf("Failed to execute note template: %v", err)
+	}
+	html := buf.String()
+
+	for _, want := range []string{
+		`<sup class="footnote-ref" id="fnref-1"><a href="#fn-1" role="doc-noteref">1</a></sup>`,
+		`<sup class="footnote-ref" id="fnref-2"><a href="#fn-2" role="doc-noteref">2</a></sup>`,
+		`<sup class="footnote-ref" id="fnref-1-2"><a href="#fn-1" role="doc-noteref">1</a></sup>`,
+		`<li id="fn-1">`,
+		`<li id="fn-2">`,
+		`href="#fnref-1-2"`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("rendered note missing %q", want)
+		}
+	}
+	for _, id := range []string{`id="fn-1"`, `id="fnref-1"`, `id="fnref-1-2"`} {
+		if n := strings.Count(html, id); n != 1 {
+			t.Errorf("expected %s exactly once, found %d times", id, n)
+		}
+	}
+}
+
+func TestRenderNoteWithBodyFootnote(t *testing.T) {
+	note := Note{
+		Slug:      "footnoted",
+		Title:     "Footnoted",
+		Thesis:    "No references here.",
+		Body:      "## Details\n\nOnly the body cites this[^source].\n",
+		Footnotes: map[string]string{"source": "The source."},
+	}
+	if err := renderNoteMarkdown(&note); err != nil {
+		t.Fatalf("renderNoteMarkdown failed: %v", err)
+	}
+
+	want := `<sup class="footnote-ref" id="fnref-1"><a href="#fn-1" role="doc-noteref">1</a></sup>`
+	if !strings.Contains(string(note.BodyHTML), want) {
+		t.Errorf("expected the body reference to be linked, got %q", note.BodyHTML)
+	}
+	if len(note.FootnotesHTML) != 1 || note.FootnotesHTML[0].ID != "fn-1" {
+		t.Errorf("unexpected footnotes %+v", note.FootnotesHTML)
+	}
+}
+
+func TestLinkFootnotesErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		note    Note
+		wantErr string
+	}{
+		{
+			name:    "missing footnote",
+			note:    Note{Thesis: "Claim[^1]"},
+			wantErr: "without a footnote: 1",
+		},
+		{
+			name:    "unused footnote",
+			note:    Note{Thesis: "Claim", Footnotes: map[string]string{"1": "Unused"}},
+			wantErr: "never referenced: 1",
+		},
+		{
+			name:    "missing footnote in body",
+			note:    Note{Thesis: "Claim", Body: "Body claim[^2]"},
+			wantErr: "without a footnote: 2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := renderNoteMarkdown(&tt.note)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
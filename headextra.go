@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// headExtraElements are the elements a note's head_extra field may contain
+var headExtraElements = map[string]bool{"style": true, "link": true, "meta": true}
+
+// checkHeadExtra returns an error describing the first part of s that may not
+// be injected into a page head: elements other than style, link, and meta,
+// event handler attributes, javascript: URLs, http-equiv pragmas such as a
+// refresh redirect, text outside a style element, or a style element left
+// open, which would swallow the rest of the page
+func checkHeadExtra(s string) error {
+	z := html.NewTokenizer(strings.NewReader(s))
+	inStyle := false
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if errors.Is(z.Err(), io.EOF) {
+				if inStyle {
+					return errors.New("head_extra has a style element without a closing </style>")
+				}
+				return nil
+			}
+			return z.Err()
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			if !headExtraElements[tok.Data] {
+				return fmt.Errorf("head_extra may only contain style, link, and meta elements, found <%s>", tok.Data)
+			}
+			for _, attr := range tok.Attr {
+				if attr.Key == "http-equiv" {
+					return fmt.Errorf("head_extra may not set http-equiv %q on <%s>", attr.Val, tok.Data)
+				}
+				if strings.HasPrefix(attr.Key, "on") {
+					return fmt.Errorf("head_extra may not set the event handler %s on <%s>", attr.Key, tok.Data)
+				}
+				if strings.HasPrefix(strings.ToLower(strings.TrimSpace(attr.Val)), "javascript:") {
+					return fmt.Errorf("head_extra may not use a javascript: URL in %s on <%s>", attr.Key, tok.Data)
+				}
+			}
+			inStyle = tok.Data == "style"
+		case html.EndTagToken:
+			tok := z.Token()
+			if !headExtraElements[tok.Data] {
+				return fmt.Errorf("head_extra may only contain style, link, and meta elements, found </%s>", tok.Data)
+			}
+			inStyle = false
+		case html.TextToken:
+			if !inStyle && strings.TrimSpace(string(z.Text())) != "" {
+				return errors.New("head_extra may only contain text inside a style element")
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"html/template"
+	"strings"
+	"testing"
+)
+
+func TestCheckHeadExtra(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{"style", "<style>.note-detail h1 { color: teal; }</style>", ""},
+		{"link and meta", `<link rel="preload" href="/fonts/serif.woff2" as="font">` + "\n" + `<meta name="theme-color" content="#008080">`, ""},
+		{"script", "<script>alert(1)</script>", "found <script>"},
+		{"body element", "<div>Hello</div>", "found <div>"},
+		{"event handler", `<link rel="stylesheet" href="/x.css" onload="alert(1)">`, "event handler onload"},
+		{"javascript url", `<link rel="stylesheet" href="javascript:alert(1)">`, "javascript: URL in href"},
+		{"refresh redirect", `<meta http-equiv="refresh" content="0;url=https://evil.example">`, `http-equiv "refresh"`},
+		{"set cookie", `<meta HTTP-EQUIV="Set-Cookie" content="session=1">`, `http-equiv "Set-Cookie"`},
+		{"unclosed style", "<style>.note-detail h1 { color: teal; }", "without a closing </style>"},
+		{"stray text", "Hello", "only contain text inside a style element"},
+		{"style hiding a script", "<style></style><script>alert(1)</script>", "found <script>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkHeadExtra(tt.input)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestNotePageHeadExtra(t *testing.T) {
+	tmpl, err := parseTemplate(siteFS, templateFuncs(Config{}, nil), "templates/note.html", "templates/footer.html")
+	if err != nil {
+		t.Fatalf("Failed to parse note template: %v", err)
+	}
+
+	style := "<style>.note-detail h1 { color: teal; }</style>"
+	data := NotePageData{Note: Note{Slug: "styled", Title: "Styled", HeadExtraHTML: template.HTML(style)}}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		t.Fatalf("Failed to execute note template: %v", err)
+	}
+
+	head, _, _ := strings.Cut(buf.String(), "</head>")
+	if !strings.Contains(head, style) {
+		t.Errorf("expected head_extra in the page head:\n%s", head)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+)
+
+// highlightCSSFile is the stylesheet for highlighted code, written to the
+// output directory when any note has an example_lang
+const highlightCSSFile = "highlight.css"
+
+// highlightStyle and highlightDarkStyle are the chroma styles used for
+// highlighted code in light and dark mode
+const (
+	highlightStyle     = "github"
+	highlightDarkStyle = "github-dark"
+)
+
+// highlightFormatter renders tokens with CSS classes so the colors come
+// from highlightCSSFile rather than inline styles
+var highlightFormatter = html.New(html.WithClasses(true))
+
+// highlightCode renders code as syntax highlighted HTML using the lexer for
+// lang, which may be a language name, alias, or file extension
+func highlightCode(code, lang string) (template.HTML, error) {
+	lexer := lexers.Get(lang)
+	if lexer == nil {
+		return "", fmt.Errorf("unknown example_lang %q", lang)
+	}
+
+	iterator, err := chroma.Coalesce(lexer).Tokenise(nil, code)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := highlightFormatter.Format(&buf, styles.Get(highlightStyle), iterator); err != nil {
+		return "", err
+	}
+	return template.HTML(buf.String()), nil
+}
+
+// hasHighlightedExamples reports whether any note has an example_lang
+func hasHighlightedExamples(notes []Note) bool {
+	for _, note := range notes {
+		if note.ExampleLang != "" && note.Example != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// generateHighlightCSS writes highlightCSSFile with the light style, and the
+// dark style for readers who prefer a dark color scheme
+func generateHighlightCSS(outDir string) error {
+	var buf bytes.Buffer
+	if err := highlightFormatter.WriteCSS(&buf, styles.Get(highlightStyle)); err != nil {
+		return err
+	}
+	buf.WriteString("@media (prefers-color-scheme: dark) {\n")
+	if err := highlightFormatter.WriteCSS(&buf, styles.Get(highlightDarkStyle)); err != nil {
+		return err
+	}
+	buf.WriteString("}\n")
+	return os.WriteFile(filepath.Join(outDir, highlightCSSFile), buf.Bytes(), 0644)
+}
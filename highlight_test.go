@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHighlightCode(t *testing.T) {
+	html, err := highlightCode("func main() {\n\tfmt.Println(\"hi\")\n}\n", "go")
+	if err != nil {
+		t.Fatalf("highlightCode returned error: %v", err)
+	}
+
+	got := string(html)
+	if !strings.Contains(got, `<pre class="chroma">`) {
+		t.Errorf("expected a chroma pre block, got %q", got)
+	}
+	if !strings.Contains(got, `<span class="kd">func</span>`) {
+		t.Errorf("expected the func keyword in a token span, got %q", got)
+	}
+	if !strings.Contains(got, `<span class="s">&#34;hi&#34;</span>`) {
+		t.Errorf("expected the string literal in an escaped token span, got %q", got)
+	}
+
+	if _, err := highlightCode("x", "not-a-language"); err == nil {
+		t.Error("expected an error for an unknown language")
+	}
+}
+
+func TestRenderNoteMarkdownHighlightsExample(t *testing.T) {
+	note := Note{Example: "x := 1 // [^not-a-footnote]", ExampleLang: "go"}
+	if err := renderNoteMarkdown(&note); err != nil {
+		t.Fatalf("renderNoteMarkdown returned error: %v", err)
+	}
+	if !strings.Contains(string(note.ExampleHTML), `class="chroma"`) {
+		t.Errorf("expected a highlighted example, got %q", note.ExampleHTML)
+	}
+
+	note = Note{Example: "Plain **text**"}
+	if err := renderNoteMarkdown(&note); err != nil {
+		t.Fatalf("renderNoteMarkdown returned error: %v", err)
+	}
+	if !strings.Contains(string(note.ExampleHTML), "<strong>text</strong>") {
+		t.Errorf("expected Markdown without example_lang, got %q", note.ExampleHTML)
+	}
+}
+
+func TestGenerateHighlightCSS(t *testing.T) {
+	outDir := t.TempDir()
+	if err := generateHighlightCSS(outDir); err != nil {
+		t.Fatalf("generateHighlightCSS returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outDir, highlightCSSFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{".chroma .kd", "@media (prefers-color-scheme: dark)"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("%s does not contain %q", highlightCSSFile, want)
+		}
+	}
+}
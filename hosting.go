@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// redirectsNetlify selects the Netlify _redirects format for alias redirects
+const redirectsNetlify = "netlify"
+
+// headersNetlify selects the Netlify _headers format, which Cloudflare Pages
+// also reads
+const headersNetlify = "netlify"
+
+// immutableCacheControl lets browsers keep fingerprinted assets for a year,
+// since a changed asset is written under a new name
+const immutableCacheControl = "public, max-age=31536000, immutable"
+
+// htmlCacheControl makes browsers revalidate pages on every visit so new
+// content and asset references are picked up at once
+const htmlCacheControl = "public, max-age=0, must-revalidate"
+
+// generatePagesFiles writes the files GitHub Pages uses to configure hosting:
+// CNAME from the CNAME environment variable when set, and an empty .nojekyll
+// so paths starting with an underscore are served as-is
+func generatePagesFiles(outDir string) error {
+	if cname := os.Getenv("CNAME"); cname != "" {
+		if err := os.WriteFile(filepath.Join(outDir, "CNAME"), []byte(cname+"\n"), 0644); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(filepath.Join(outDir, ".nojekyll"), nil, 0644)
+}
+
+// generateNetlifyRedirects writes a Netlify _redirects file with a permanent
+// redirect from each alias of every note to the note's current path
+func generateNetlifyRedirects(cfg Config, notes []Note) error {
+	var b strings.Builder
+	for _, note := range notes {
+		for _, alias := range note.Aliases {
+			fmt.Fprintf(&b, "%s/%s/* %s%s 301\n", cfg.BasePath, alias, cfg.BasePath, notePath(cfg, note.Slug))
+		}
+	}
+	return os.WriteFile(filepath.Join(cfg.OutputDir, "_redirects"), []byte(b.String()), 0644)
+}
+
+// generateNetlifyHeaders writes a Netlify _headers file. Every path gets the
+// configured security headers, plus the csp and a Vary: Accept-Encoding for
+// precompressed output when enabled. Each fingerprinted asset in assets is
+// cached for a year, and each page in the output directory is revalidated on
+// every visit. Pages are listed individually because hosts combine the
+// values of a header set by more than one matching rule.
+func generateNetlifyHeaders(cfg Config, assets map[string]string) error {
+	site := maps.Clone(cfg.SecurityHeaders)
+	if site == nil {
+		site = make(map[string]string)
+	}
+	if cfg.CSP != "" {
+		site["Content-Security-Policy"] = cfg.CSP
+	}
+	if cfg.Compress {
+		site["Vary"] = "Accept-Encoding"
+	}
+
+	var b strings.Builder
+	writeBlock := func(urlPath string, headers map[string]string) {
+		fmt.Fprintf(&b, "%s%s\n", cfg.BasePath, urlPath)
+		for _, name := range slices.Sorted(maps.Keys(headers)) {
+			fmt.Fprintf(&b, "  %s: %s\n", name, headers[name])
+		}
+		b.WriteString("\n")
+	}
+
+	if len(site) > 0 {
+		writeBlock("/*", site)
+	}
+
+	for _, asset := range slices.Sorted(maps.Values(assets)) {
+		writeBlock("/"+asset, map[string]string{"Cache-Control": immutableCacheControl})
+	}
+
+	var pages []string
+	err := fs.WalkDir(os.DirFS(cfg.OutputDir), ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || path.Ext(name) != ".html" {
+			return err
+		}
+		switch {
+		case name == "index.html":
+			pages = append(pages, "/")
+		case path.Base(name) == "index.html":
+			pages = append(pages, "/"+path.Dir(name)+"/")
+		default:
+			pages = append(pages, "/"+name, "/"+strings.TrimSuffix(name, ".html"))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, page := range pages {
+		writeBlock(page, map[string]string{"Cache-Control": htmlCacheControl})
+	}
+
+	return os.WriteFile(filepath.Join(cfg.OutputDir, "_headers"), []byte(b.String()), 0644)
+}
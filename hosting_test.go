@@ -0,0 +1,107 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGeneratePagesFiles(t *testing.T) {
+	t.Setenv("CNAME", "notes.example.com")
+	outDir := t.TempDir()
+
+	if err := generatePagesFiles(outDir); err != nil {
+		t.Fatalf("generatePagesFiles returned error: %v", err)
+	}
+
+	cname, err := os.ReadFile(filepath.Join(outDir, "CNAME"))
+	if err != nil {
+		t.Fatalf("expected CNAME to be written: %v", err)
+	}
+	if string(cname) != "notes.example.com\n" {
+		t.Errorf("CNAME = %q, want %q", cname, "notes.example.com\n")
+	}
+	if _, err := os.Stat(filepath.Join(outDir, ".nojekyll")); err != nil {
+		t.Errorf("expected .nojekyll to be written: %v", err)
+	}
+}
+
+func TestGeneratePagesFilesWithoutCNAME(t *testing.T) {
+	t.Setenv("CNAME", "")
+	outDir := t.TempDir()
+
+	if err := generatePagesFiles(outDir); err != nil {
+		t.Fatalf("generatePagesFiles returned error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "CNAME")); err == nil {
+		t.Error("CNAME should not be written when the environment variable is unset")
+	}
+	if _, err := os.Stat(filepath.Join(outDir, ".nojekyll")); err != nil {
+		t.Errorf("expected .nojekyll to be written: %v", err)
+	}
+}
+
+func TestGenerateNetlifyRedirects(t *testing.T) {
+	cfg := testConfig(t)
+	outDir := cfg.OutputDir
+	notes := []Note{
+		{Slug: "current", Aliases: []string{"old", "older"}},
+		{Slug: "unchanged"},
+		{Slug: "renamed", Aliases: []string{"first-name"}},
+	}
+
+	if err := generateNetlifyRedirects(cfg, notes); err != nil {
+		t.Fatalf("generateNetlifyRedirects returned error: %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(outDir, "_redirects"))
+	if err != nil {
+		t.Fatalf("expected _redirects to be written: %v", err)
+	}
+	want := "/old/* /current/ 301\n/older/* /current/ 301\n/first-name/* /renamed/ 301\n"
+	if string(got) != want {
+		t.Errorf("_redirects = %q, want %q", got, want)
+	}
+}
+
+func TestGenerateNetlifyHeaders(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Compress = true
+	cfg.CSP = "default-src 'self'"
+	cfg.SecurityHeaders = map[string]string{"X-Frame-Options": "DENY", "Referrer-Policy": "strict-origin-when-cross-origin"}
+	for _, name := range []string{"index.html", "404.html", "go/concurrency/index.html", "style.0123456789.css"} {
+		path := filepath.Join(cfg.OutputDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	assets := map[string]string{"style.css": "style.0123456789.css"}
+	if err := generateNetlifyHeaders(cfg, assets); err != nil {
+		t.Fatalf("generateNetlifyHeaders returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(cfg.OutputDir, "_headers"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"/*\n  Content-Security-Policy: default-src 'self'\n  Referrer-Policy: strict-origin-when-cross-origin\n  Vary: Accept-Encoding\n  X-Frame-Options: DENY\n\n",
+		"/style.0123456789.css\n  Cache-Control: public, max-age=31536000, immutable\n\n",
+		"/\n  Cache-Control: public, max-age=0, must-revalidate\n\n",
+		"/404.html\n  Cache-Control: public, max-age=0, must-revalidate\n\n",
+		"/404\n  Cache-Control: public, max-age=0, must-revalidate\n\n",
+		"/go/concurrency/\n  Cache-Control: public, max-age=0, must-revalidate\n\n",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("_headers does not contain block %q:\n%s", want, data)
+		}
+	}
+	if strings.Count(string(data), "Cache-Control") != 5 {
+		t.Errorf("expected a Cache-Control header only on each asset and page:\n%s", data)
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// idPattern matches note IDs, such as a UUID or a short code
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]*$`)
+
+// idURNPrefix turns a note ID into the URI feeds identify the note by
+const idURNPrefix = "urn:note:"
+
+// checkNoteIDs returns an error naming both files when two notes share an ID
+func checkNoteIDs(notes []Note) error {
+	seen := make(map[string]string, len(notes))
+	for _, note := range notes {
+		if note.ID == "" {
+			continue
+		}
+		if source, ok := seen[note.ID]; ok {
+			return fmt.Errorf("duplicate id %q in %s and %s", note.ID, source, note.Source)
+		}
+		seen[note.ID] = note.Source
+	}
+	return nil
+}
+
+// noteFeedID returns the identifier feeds use for note. A note with an id
+// keeps the same identifier when its slug changes, so subscribers don't see
+// it again as a new post; other notes are identified by their canonical URL.
+func noteFeedID(cfg Config, note Note) string {
+	if note.ID == "" {
+		return noteCanonicalURL(cfg, note.Slug)
+	}
+	return idURNPrefix + note.ID
+}
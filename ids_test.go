@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckNoteIDs(t *testing.T) {
+	notes := []Note{
+		{Slug: "first", ID: "a1", Source: "content/first.yaml"},
+		{Slug: "second", Source: "content/second.yaml"},
+		{Slug: "third", Source: "content/third.yaml"},
+	}
+	if err := checkNoteIDs(notes); err != nil {
+		t.Errorf("notes without an id should not conflict, got %v", err)
+	}
+
+	notes[2].ID = "a1"
+	err := checkNoteIDs(notes)
+	if err == nil || !strings.Contains(err.Error(), "content/first.yaml") || !strings.Contains(err.Error(), "content/third.yaml") {
+		t.Errorf("expected an error naming both files, got %v", err)
+	}
+}
+
+func TestFeedsUseNoteID(t *testing.T) {
+	cfg := testConfig(t)
+	notes := []Note{
+		{Slug: "renamed", ID: "7f3c9a", Title: "Renamed", Thesis: "Moved.", Date: "2024-02-01"},
+		{Slug: "plain", Title: "Plain", Thesis: "Unchanged.", Date: "2024-01-01"},
+	}
+	for _, generate := range []func(Config, []Note) error{generateRSS, generateAtom, generateJSONFeed} {
+		if err := generate(cfg, notes); err != nil {
+			t.Fatalf("generating feed: %v", err)
+		}
+	}
+
+	rss, err := os.ReadFile(filepath.Join(cfg.OutputDir, "rss.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<guid isPermaLink="false">urn:note:7f3c9a</guid>`,
+		`<guid>https://notes.example.com/plain/</guid>`,
+	} {
+		if !strings.Contains(string(rss), want) {
+			t.Errorf("rss.xml does not contain %s:\n%s", want, rss)
+		}
+	}
+
+	atom, err := os.ReadFile(filepath.Join(cfg.OutputDir, "atom.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(atom), "<id>urn:note:7f3c9a</id>") {
+		t.Errorf("atom.xml does not identify the note by its id:\n%s", atom)
+	}
+
+	data, err := os.ReadFile(filepath.Join(cfg.OutputDir, "feed.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var feed JSONFeed
+	if err := json.Unmarshal(data, &feed); err != nil {
+		t.Fatalf("feed.json is not valid JSON: %v", err)
+	}
+	first := feed.Items[0]
+	if first.ID != "urn:note:7f3c9a" || first.URL != "https://notes.example.com/renamed/" {
+		t.Errorf("expected the id with the current URL, got id %q and url %q", first.ID, first.URL)
+	}
+}
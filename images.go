@@ -0,0 +1,138 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// imageWidths are the widths in pixels of the resized variants written for
+// each cover image. Widths at or above the source image's width are skipped.
+var imageWidths = []int{400, 800, 1200}
+
+// jpegQuality is the quality resized JPEG variants are encoded with
+const jpegQuality = 85
+
+// generateImageVariants writes resized copies of each note's cover image to
+// the output directory and sets the note's ImageSrcset to list them along
+// with the original. Only JPEG and PNG images served from static are
+// resized; other images are left without a srcset. It returns the number of
+// variants written.
+func generateImageVariants(cfg Config, staticFS fs.FS, notes []Note) (int, error) {
+	srcsets := make(map[string]string)
+	written := 0
+	for i, note := range notes {
+		if note.Image == "" {
+			continue
+		}
+		srcset, ok := srcsets[note.Image]
+		if !ok {
+			var n int
+			var err error
+			if srcset, n, err = resizeImage(cfg, staticFS, note.Image); err != nil {
+				return written, fmt.Errorf("resizing image for %s: %w", note.Slug, err)
+			}
+			srcsets[note.Image] = srcset
+			written += n
+		}
+		notes[i].ImageSrcset = srcset
+	}
+	return written, nil
+}
+
+// resizeImage writes the variants of the static image at the site path ref
+// and returns their srcset and how many were written. It returns an empty
+// srcset for images it cannot resize.
+func resizeImage(cfg Config, staticFS fs.FS, ref string) (string, int, error) {
+	if !strings.HasPrefix(ref, "/") || strings.HasPrefix(ref, "//") {
+		return "", 0, nil
+	}
+	name := strings.TrimPrefix(ref, "/")
+	ext := strings.ToLower(path.Ext(name))
+	if ext != ".jpg" && ext != ".jpeg" && ext != ".png" {
+		return "", 0, nil
+	}
+
+	f, err := staticFS.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", 0, nil
+	} else if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+	src, _, err := image.Decode(f)
+	if err != nil {
+		return "", 0, fmt.Errorf("decoding %s: %w", name, err)
+	}
+
+	var srcset []string
+	base := strings.TrimSuffix(name, path.Ext(name))
+	for _, width := range imageWidths {
+		if width >= src.Bounds().Dx() {
+			break
+		}
+		variant := fmt.Sprintf("%s-%dw%s", base, width, path.Ext(name))
+		if err := writeImage(filepath.Join(cfg.OutputDir, filepath.FromSlash(variant)), scaleImage(src, width), ext); err != nil {
+			return "", 0, err
+		}
+		srcset = append(srcset, fmt.Sprintf("%s/%s %dw", cfg.BasePath, variant, width))
+	}
+	if len(srcset) == 0 {
+		return "", 0, nil
+	}
+	srcset = append(srcset, fmt.Sprintf("%s/%s %dw", cfg.BasePath, name, src.Bounds().Dx()))
+	return strings.Join(srcset, ", "), len(srcset) - 1, nil
+}
+
+// scaleImage returns src scaled down to width, keeping its aspect ratio.
+// Each destination pixel is the average of the source pixels it covers.
+func scaleImage(src image.Image, width int) *image.RGBA64 {
+	b := src.Bounds()
+	height := max(1, (b.Dy()*width+b.Dx()/2)/b.Dx())
+	dst := image.NewRGBA64(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		y0 := b.Min.Y + y*b.Dy()/height
+		y1 := max(y0+1, b.Min.Y+(y+1)*b.Dy()/height)
+		for x := 0; x < width; x++ {
+			x0 := b.Min.X + x*b.Dx()/width
+			x1 := max(x0+1, b.Min.X+(x+1)*b.Dx()/width)
+
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r, g, bl, a = r+uint64(pr), g+uint64(pg), bl+uint64(pb), a+uint64(pa)
+					n++
+				}
+			}
+			i := dst.PixOffset(x, y)
+			for j, v := range []uint64{r / n, g / n, bl / n, a / n} {
+				dst.Pix[i+2*j] = uint8(v >> 8)
+				dst.Pix[i+2*j+1] = uint8(v)
+			}
+		}
+	}
+	return dst
+}
+
+// writeImage encodes img to path in the format of the extension ext
+func writeImage(path string, img image.Image, ext string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if ext == ".png" {
+		return png.Encode(f, img)
+	}
+	return jpeg.Encode(f, img, &jpeg.Options{Quality: jpegQuality})
+}
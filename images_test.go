@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestGenerateImageVariants(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 1000, 500))
+	for y := 0; y < 500; y++ {
+		for x := 0; x < 1000; x++ {
+			src.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, src); err != nil {
+		t.Fatal(err)
+	}
+	staticFS := fstest.MapFS{"img/cover.png": {Data: buf.Bytes()}}
+
+	cfg := testConfig(t)
+	cfg.BasePath = "/notes"
+	if err := os.MkdirAll(filepath.Join(cfg.OutputDir, "img"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	notes := []Note{
+		{Slug: "a", Image: "/img/cover.png"},
+		{Slug: "b", Image: "/img/cover.png"},
+		{Slug: "remote", Image: "https://example.com/cover.png"},
+		{Slug: "missing", Image: "/img/missing.png"},
+	}
+
+	written, err := generateImageVariants(cfg, staticFS, notes)
+	if err != nil {
+		t.Fatalf("generateImageVariants returned error: %v", err)
+	}
+	if written != 2 {
+		t.Errorf("expected 2 variants below the source width, got %d", written)
+	}
+
+	want := "/notes/img/cover-400w.png 400w, /notes/img/cover-800w.png 800w, /notes/img/cover.png 1000w"
+	for _, note := range notes[:2] {
+		if note.ImageSrcset != want {
+			t.Errorf("%s srcset = %q, want %q", note.Slug, note.ImageSrcset, want)
+		}
+	}
+	for _, note := range notes[2:] {
+		if note.ImageSrcset != "" {
+			t.Errorf("%s should have no srcset, got %q", note.Slug, note.ImageSrcset)
+		}
+	}
+
+	f, err := os.Open(filepath.Join(cfg.OutputDir, "img", "cover-400w.png"))
+	if err != nil {
+		t.Fatalf("expected the 400w variant to be written: %v", err)
+	}
+	defer f.Close()
+	variant, err := png.DecodeConfig(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if variant.Width != 400 || variant.Height != 200 {
+		t.Errorf("400w variant is %dx%d, want 400x200", variant.Width, variant.Height)
+	}
+}
+
+func TestNotePageImageSrcset(t *testing.T) {
+	tmpl, err := parseTemplate(siteFS, templateFuncs(Config{}, nil), "templates/note.html", "templates/footer.html")
+	if err != nil {
+		t.Fatalf("Failed to parse note template: %v", err)
+	}
+
+	note := Note{Slug: "a", Title: "A", Image: "/cover.png", ImageAlt: "Cover", ImageSrcset: "/cover-400w.png 400w, /cover.png 1000w"}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, NotePageData{Note: note}); err != nil {
+		t.Fatalf("Failed to execute note template: %v", err)
+	}
+	if want := `srcset="/cover-400w.png 400w, /cover.png 1000w"`; !strings.Contains(buf.String(), want) {
+		t.Errorf("rendered note page does not contain %s", want)
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+)
+
+// buildCacheFile records the inputs of each note page in the output
+// directory so incremental builds can skip pages that have not changed
+const buildCacheFile = ".buildcache"
+
+// buildCache tracks the hash of the inputs each note page was rendered from.
+// A note page's inputs are its content file, its page data, which covers
+// everything it shows from other notes, the fields rendered from the note
+// during the build, and the templates, base path, and fingerprinted assets
+// shared by all pages.
+type buildCache struct {
+	cfg     Config
+	fsys    fs.FS
+	shared  []byte
+	hashes  map[string]string
+	next    map[string]string
+	Skipped int
+}
+
+// loadBuildCache reads the build cache from the output directory. A missing
+// or unreadable cache is treated as empty, so every page is rendered.
+func loadBuildCache(cfg Config, fsys fs.FS, assets map[string]string) (*buildCache, error) {
+	h := sha256.New()
+	// Template functions build page URLs from the base path and URL style
+	h.Write([]byte(cfg.BasePath + "\n" + cfg.URLStyle))
+	err := fs.WalkDir(fsys, "templates", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+		h.Write([]byte(path))
+		h.Write(data)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := json.Marshal(assets)
+	if err != nil {
+		return nil, err
+	}
+	h.Write(manifest)
+
+	cache := &buildCache{
+		cfg:    cfg,
+		fsys:   fsys,
+		shared: h.Sum(nil),
+		hashes: make(map[string]string),
+		next:   make(map[string]string),
+	}
+	cache.hashes = readBuildCache(cfg.OutputDir)
+	return cache, nil
+}
+
+// readBuildCache returns the page hashes recorded by the previous build in
+// outDir, or an empty map when there are none
+func readBuildCache(outDir string) map[string]string {
+	hashes := make(map[string]string)
+	if data, err := os.ReadFile(filepath.Join(outDir, buildCacheFile)); err == nil {
+		if err := json.Unmarshal(data, &hashes); err != nil {
+			return make(map[string]string)
+		}
+	}
+	return hashes
+}
+
+// pruneOutput prepares the output directory for an incremental build by
+// removing everything except the build cache and the pages it records for
+// notes still in the site, so listings, feeds, and pages of deleted notes,
+// tags, or aliases never go stale. Directories left empty are removed.
+func pruneOutput(cfg Config, notes []Note) error {
+	outDir := cfg.OutputDir
+	keep := map[string]bool{buildCacheFile: true}
+	previous := readBuildCache(outDir)
+	for _, note := range notes {
+		if _, ok := previous[note.Slug]; !ok {
+			continue
+		}
+		for _, file := range notePageFiles(cfg, note.Slug) {
+			keep[file] = true
+		}
+	}
+
+	var dirs []string
+	err := filepath.WalkDir(outDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(outDir, path)
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if rel != "." {
+				dirs = append(dirs, path)
+			}
+			return nil
+		}
+		if keep[rel] {
+			return nil
+		}
+		return os.Remove(path)
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Remove the deepest directories first so their parents can empty too
+	for i := len(dirs) - 1; i >= 0; i-- {
+		entries, err := os.ReadDir(dirs[i])
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			if err := os.Remove(dirs[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// unchanged records the inputs of the page for data.Note and reports whether
+// they match the previous build and its pages are still in the output
+func (c *buildCache) unchanged(data NotePageData) (bool, error) {
+	source, err := fs.ReadFile(c.fsys, data.Note.Source)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return false, err
+	}
+	pageData, err := json.Marshal(data)
+	if err != nil {
+		return false, err
+	}
+	// Fields derived during the build, such as the sanitized embed, depend on
+	// config as well as the content file but are not part of the page data
+	derived, err := json.Marshal(derivedFields(data.Note))
+	if err != nil {
+		return false, err
+	}
+
+	h := sha256.New()
+	h.Write(c.shared)
+	h.Write(source)
+	h.Write(pageData)
+	h.Write(derived)
+	hash := hex.EncodeToString(h.Sum(nil))
+
+	slug := data.Note.Slug
+	c.next[slug] = hash
+	if c.hashes[slug] != hash {
+		return false, nil
+	}
+	for _, file := range notePageFiles(c.cfg, slug) {
+		if _, err := os.Stat(filepath.Join(c.cfg.OutputDir, file)); err != nil {
+			return false, nil
+		}
+	}
+	c.Skipped++
+	return true, nil
+}
+
+// save writes the cache for the next build
+func (c *buildCache) save() error {
+	data, err := json.MarshalIndent(c.next, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(c.cfg.OutputDir, buildCacheFile), data, 0644)
+}
+
+// derivedFields returns the values of the fields of note left out of its
+// JSON, which are computed during the build rather than read from content
+func derivedFields(note Note) []any {
+	v := reflect.ValueOf(note)
+	var fields []any
+	for i := range v.NumField() {
+		if v.Type().Field(i).Tag.Get("json") == "-" {
+			fields = append(fields, v.Field(i).Interface())
+		}
+	}
+	return fields
+}
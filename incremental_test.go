@@ -0,0 +1,207 @@
+package main
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestIncrementalBuildSkipsUnchangedNotes(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Incremental = true
+	if err := run(cfg, siteFS); err != nil {
+		t.Fatalf("first build failed: %v", err)
+	}
+
+	notes, err := readNotes(cfg, siteFS)
+	if err != nil {
+		t.Fatalf("readNotes failed: %v", err)
+	}
+	page := filepath.Join(cfg.OutputDir, filepath.FromSlash(notes[0].Slug), "index.html")
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(page, past, past); err != nil {
+		t.Fatalf("Chtimes failed: %v", err)
+	}
+
+	if err := run(cfg, siteFS); err != nil {
+		t.Fatalf("second build failed: %v", err)
+	}
+	info, err := os.Stat(page)
+	if err != nil {
+		t.Fatalf("note page missing after second build: %v", err)
+	}
+	if !info.ModTime().Equal(past) {
+		t.Errorf("unchanged note page was rewritten: mtime %v, want %v", info.ModTime(), past)
+	}
+}
+
+func TestBuildCacheDetectsChanges(t *testing.T) {
+	cfg := testConfig(t)
+	data := NotePageData{Note: Note{Slug: "a", Title: "A"}}
+
+	cache, err := loadBuildCache(cfg, siteFS, nil)
+	if err != nil {
+		t.Fatalf("loadBuildCache failed: %v", err)
+	}
+	if unchanged, err := cache.unchanged(data); err != nil || unchanged {
+		t.Fatalf("a note missing from the cache should be rendered, got %v, %v", unchanged, err)
+	}
+	slugDir := filepath.Join(cfg.OutputDir, "a")
+	if err := os.MkdirAll(slugDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{slugDir + ".html", filepath.Join(slugDir, "index.html")} {
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cache.save(); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	cache, err = loadBuildCache(cfg, siteFS, nil)
+	if err != nil {
+		t.Fatalf("loadBuildCache failed: %v", err)
+	}
+	if unchanged, err := cache.unchanged(data); err != nil || !unchanged {
+		t.Errorf("identical page data should be skipped, got %v, %v", unchanged, err)
+	}
+	data.Note.Title = "B"
+	if unchanged, _ := cache.unchanged(data); unchanged {
+		t.Error("changed page data should be rendered again")
+	}
+
+	cache, err = loadBuildCache(cfg, siteFS, map[string]string{"style.css": "style.abc.css"})
+	if err != nil {
+		t.Fatalf("loadBuildCache failed: %v", err)
+	}
+	data.Note.Title = "A"
+	if unchanged, _ := cache.unchanged(data); unchanged {
+		t.Error("changed assets should render every page again")
+	}
+}
+
+func TestBuildCacheDetectsDerivedFieldChanges(t *testing.T) {
+	cfg := testConfig(t)
+	data := NotePageData{Note: Note{Slug: "a", Title: "A", EmbedHTML: "<iframe></iframe>"}}
+
+	cache, err := loadBuildCache(cfg, siteFS, nil)
+	if err != nil {
+		t.Fatalf("loadBuildCache failed: %v", err)
+	}
+	if _, err := cache.unchanged(data); err != nil {
+		t.Fatalf("unchanged failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(cfg.OutputDir, "a.html"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(cfg.OutputDir, "a"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(cfg.OutputDir, "a", "index.html"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.save(); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	cache, err = loadBuildCache(cfg, siteFS, nil)
+	if err != nil {
+		t.Fatalf("loadBuildCache failed: %v", err)
+	}
+	// An embed removed by a change to embed_hosts leaves the content as is
+	data.Note.EmbedHTML = ""
+	if unchanged, _ := cache.unchanged(data); unchanged {
+		t.Error("a changed embed should render the page again")
+	}
+}
+
+func TestPruneOutputKeepsOnlyCachedNotePages(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.URLStyle = urlStyleExtension
+	cache := map[string]string{"kept": "hash", "go/gone": "hash"}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{
+		buildCacheFile:          data,
+		"kept.html":             nil,
+		"kept/index.html":       nil,
+		"go/gone.html":          nil,
+		"tags/old/index.html":   nil,
+		"api/go/gone.json":      nil,
+		"kept.html.gz":          nil,
+		"uncached.html":         nil,
+		"category/x/index.html": nil,
+	}
+	for name, data := range files {
+		path := filepath.Join(cfg.OutputDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	notes := []Note{{Slug: "kept"}, {Slug: "uncached"}}
+	if err := pruneOutput(cfg, notes); err != nil {
+		t.Fatalf("pruneOutput failed: %v", err)
+	}
+
+	var left []string
+	err = filepath.WalkDir(cfg.OutputDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(cfg.OutputDir, path)
+		if rel != "." {
+			left = append(left, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{buildCacheFile, "kept.html"}
+	if !slices.Equal(left, want) {
+		t.Errorf("expected only %v to be left, got %v", want, left)
+	}
+}
+
+func TestIncrementalBuildRemovesOutputOfDeletedNotes(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Incremental = true
+	if err := run(cfg, siteFS); err != nil {
+		t.Fatalf("first build failed: %v", err)
+	}
+
+	// Rebuild without the only note tagged product
+	fsys := fstest.MapFS{}
+	err := fs.WalkDir(siteFS, ".", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || path == "content/poc-vs-mvp.yaml" {
+			return err
+		}
+		data, err := fs.ReadFile(siteFS, path)
+		fsys[path] = &fstest.MapFile{Data: data}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := run(cfg, fsys); err != nil {
+		t.Fatalf("second build failed: %v", err)
+	}
+
+	for _, stale := range []string{"poc-vs-mvp", "api/poc-vs-mvp.json", "tags/product"} {
+		if _, err := os.Stat(filepath.Join(cfg.OutputDir, filepath.FromSlash(stale))); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed, got %v", stale, err)
+		}
+	}
+}
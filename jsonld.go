@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"html/template"
+)
+
+// ArticleJSONLD represents schema.org Article structured data for a note
+type ArticleJSONLD struct {
+	Context       string        `json:"@context"`
+	Type          string        `json:"@type"`
+	Headline      string        `json:"headline"`
+	Description   string        `json:"description"`
+	URL           string        `json:"url"`
+	DatePublished string        `json:"datePublished,omitempty"`
+	Author        *PersonJSONLD `json:"author,omitempty"`
+}
+
+// PersonJSONLD represents a schema.org Person
+type PersonJSONLD struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+// noteJSONLD returns the JSON-LD for note, safe to embed in a script element.
+// encoding/json escapes <, >, and & so the content cannot close the element.
+func noteJSONLD(note Note, canonicalURL, description string) (template.JS, error) {
+	article := ArticleJSONLD{
+		Context:       "https://schema.org",
+		Type:          "Article",
+		Headline:      note.Title,
+		Description:   description,
+		URL:           canonicalURL,
+		DatePublished: note.Date,
+	}
+	if note.Author != "" {
+		article.Author = &PersonJSONLD{Type: "Person", Name: note.Author}
+	}
+
+	data, err := json.Marshal(article)
+	if err != nil {
+		return "", err
+	}
+	return template.JS(data), nil
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNoteJSONLD(t *testing.T) {
+	note := Note{Title: `Say "hello" </script> 世界`, Date: "2024-05-01"}
+
+	js, err := noteJSONLD(note, "https://example.com/hello/", "A description")
+	if err != nil {
+		t.Fatalf("noteJSONLD returned error: %v", err)
+	}
+
+	var got ArticleJSONLD
+	if err := json.Unmarshal([]byte(js), &got); err != nil {
+		t.Fatalf("JSON-LD is not valid JSON: %v", err)
+	}
+	if got.Headline != note.Title {
+		t.Errorf("headline = %q, want %q", got.Headline, note.Title)
+	}
+	if got.Type != "Article" || got.DatePublished != "2024-05-01" {
+		t.Errorf("unexpected JSON-LD: %+v", got)
+	}
+	if strings.Contains(string(js), "</") {
+		t.Errorf("JSON-LD must not contain a closing tag: %s", js)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// lastModGit selects the last commit date of each content file as the
+// sitemap lastmod of its note
+const lastModGit = "git"
+
+// validateLastMod returns an error unless source is empty or a supported
+// source of sitemap lastmod dates
+func validateLastMod(source string) error {
+	if source != "" && source != lastModGit {
+		return fmt.Errorf("unsupported lastmod source %q (supported: %s)", source, lastModGit)
+	}
+	return nil
+}
+
+// gitCommitTime returns the output of git log for the last commit touching
+// path, which is empty when the file has never been committed. It is a
+// variable so tests can stub out git.
+var gitCommitTime = func(path string) (string, error) {
+	out, err := exec.Command("git", "log", "-1", "--format=%cI", "--", path).Output()
+	return string(out), err
+}
+
+// setGitLastModified sets GitModified on each note to the last commit date of
+// its content file. Notes whose file has not been committed keep their date
+// fields. When git fails, such as outside a checkout, a warning is reported
+// and the remaining notes are left unchanged.
+func setGitLastModified(notes []Note, warnings *reporter) {
+	for i := range notes {
+		out, err := gitCommitTime(notes[i].Source)
+		if err != nil {
+			warnings.Warnf("reading git history for lastmod: %v; using note dates instead", err)
+			return
+		}
+		out = strings.TrimSpace(out)
+		if out == "" {
+			continue
+		}
+		committed, err := time.Parse(time.RFC3339, out)
+		if err != nil {
+			warnings.Warnf("parsing git commit time %q for %s: %v", out, notes[i].Source, err)
+			continue
+		}
+		notes[i].GitModified = committed.UTC().Format(time.RFC3339)
+	}
+}
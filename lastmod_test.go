@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// stubGit replaces git with commit times looked up by path for the test
+func stubGit(t *testing.T, times map[string]string, err error) {
+	t.Helper()
+	original := gitCommitTime
+	gitCommitTime = func(path string) (string, error) {
+		return times[path], err
+	}
+	t.Cleanup(func() { gitCommitTime = original })
+}
+
+func TestSetGitLastModified(t *testing.T) {
+	stubGit(t, map[string]string{
+		"content/committed.yaml": "2025-05-20T14:03:11+02:00\n",
+	}, nil)
+
+	notes := []Note{
+		{Slug: "committed", Source: "content/committed.yaml", Date: "2024-01-01"},
+		{Slug: "new", Source: "content/new.yaml", Date: "2025-06-01"},
+	}
+	setGitLastModified(notes, newReporter(false))
+
+	if got := notes[0].GitModified; got != "2025-05-20T12:03:11Z" {
+		t.Errorf("expected the commit time in UTC, got %q", got)
+	}
+	if got := notes[1].GitModified; got != "" {
+		t.Errorf("uncommitted files should keep their note dates, got %q", got)
+	}
+
+	cfg := testConfig(t)
+	if err := generateSitemap(cfg, notes, defaultSitemapMaxURLs); err != nil {
+		t.Fatalf("generateSitemap returned error: %v", err)
+	}
+	var sitemap Sitemap
+	readXMLFile(t, filepath.Join(cfg.OutputDir, "sitemap.xml"), &sitemap)
+	lastMods := make(map[string]string)
+	for _, url := range sitemap.URLs {
+		lastMods[url.Loc] = url.LastMod
+	}
+	if got := lastMods["https://notes.example.com/committed/"]; got != "2025-05-20T12:03:11Z" {
+		t.Errorf("expected the commit time as lastmod, got %q", got)
+	}
+	if got := lastMods["https://notes.example.com/new/"]; got != "2025-06-01" {
+		t.Errorf("expected the note date as lastmod, got %q", got)
+	}
+}
+
+func TestSetGitLastModifiedWithoutGit(t *testing.T) {
+	stubGit(t, nil, errors.New("exec: \"git\": executable file not found in $PATH"))
+
+	var out bytes.Buffer
+	notes := []Note{{Slug: "a", Source: "content/a.yaml"}, {Slug: "b", Source: "content/b.yaml"}}
+	setGitLastModified(notes, &reporter{out: &out})
+
+	if notes[0].GitModified != "" || notes[1].GitModified != "" {
+		t.Error("notes should keep their dates when git is unavailable")
+	}
+	if strings.Count(out.String(), "Warning:") != 1 {
+		t.Errorf("expected a single warning, got %q", out.String())
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// linkCheckTimeout bounds each HEAD request made by -check-links
+	linkCheckTimeout = 10 * time.Second
+
+	// linkCheckConcurrency limits how many link checks run at once
+	linkCheckConcurrency = 8
+)
+
+// externalLinks returns the unique http(s) link URLs across notes that do not
+// point at this site, sorted for deterministic checking and reporting
+func externalLinks(notes []Note, baseURL string) []string {
+	seen := make(map[string]bool)
+	var urls []string
+	for _, note := range notes {
+		for _, link := range note.Links {
+			if _, ok := internalSlug(link.URL, baseURL); ok {
+				continue
+			}
+			u, err := url.Parse(link.URL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+				continue
+			}
+			if !seen[link.URL] {
+				seen[link.URL] = true
+				urls = append(urls, link.URL)
+			}
+		}
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// checkExternalLinks issues a HEAD request to each URL using at most
+// concurrency requests at a time, and returns a warning for every URL that
+// fails or responds with a status outside 2xx/3xx
+func checkExternalLinks(client *http.Client, urls []string, concurrency int) []string {
+	results := make([]string, len(urls))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, u := range urls {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = checkLink(client, u)
+		}()
+	}
+	wg.Wait()
+
+	var warnings []string
+	for _, w := range results {
+		if w != "" {
+			warnings = append(warnings, w)
+		}
+	}
+	return warnings
+}
+
+// checkLink returns a warning describing why url is unreachable, or an empty
+// string when it responds successfully
+func checkLink(client *http.Client, url string) string {
+	resp, err := client.Head(url)
+	if err != nil {
+		return fmt.Sprintf("%s: %v", url, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return fmt.Sprintf("%s: %s", url, resp.Status)
+	}
+	return ""
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestExternalLinks(t *testing.T) {
+	notes := []Note{
+		{Slug: "a", Links: []Link{
+			{URL: "https://go.dev/doc"},
+			{URL: "/b/"},
+			{URL: "https://notes.example.com/b/"},
+		}},
+		{Slug: "b", Links: []Link{
+			{URL: "https://go.dev/doc"},
+			{URL: "mailto:someone@example.com"},
+			{URL: "http://example.org"},
+		}},
+	}
+
+	got := externalLinks(notes, "https://notes.example.com")
+	want := []string{"http://example.org", "https://go.dev/doc"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("link %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestCheckExternalLinks(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Method != http.MethodHead {
+			t.Errorf("expected HEAD request, got %s", r.Method)
+		}
+		switch r.URL.Path {
+		case "/ok":
+			w.WriteHeader(http.StatusOK)
+		case "/moved":
+			http.Redirect(w, r, "/ok", http.StatusMovedPermanently)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	urls := []string{srv.URL + "/ok", srv.URL + "/moved", srv.URL + "/missing"}
+	warnings := checkExternalLinks(srv.Client(), urls, 1)
+	if len(warnings) != 1 {
+		t.Fatalf("expected 1 warning, got %v", warnings)
+	}
+	if !strings.Contains(warnings[0], "/missing") || !strings.Contains(warnings[0], "404") {
+		t.Errorf("unexpected warning %q", warnings[0])
+	}
+	if n := requests.Load(); n != 4 {
+		t.Errorf("expected 4 requests including the redirect, got %d", n)
+	}
+}
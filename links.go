@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// hrefAttr matches the target of each link in rendered Markdown
+var hrefAttr = regexp.MustCompile(`href="([^"]*)"`)
+
+// internalSlug returns the slug referenced by a link URL when the link points
+// at a note on this site. Relative URLs such as /slug/, /slug.html, and
+// slug.html are always internal, with any base path from baseURL removed.
+// Absolute URLs are only internal when they start with baseURL; all other
+// absolute URLs are treated as external and ignored. Links to the site root
+// are not considered note references.
+func internalSlug(link, baseURL string) (string, bool) {
+	if baseURL != "" && strings.HasPrefix(link, baseURL) {
+		link = strings.TrimPrefix(link, baseURL)
+	}
+
+	u, err := url.Parse(link)
+	if err != nil || u.Scheme != "" || u.Host != "" {
+		return "", false
+	}
+
+	p := u.Path
+	if base, err := url.Parse(baseURL); err == nil && base.Path != "" && strings.HasPrefix(p, base.Path+"/") {
+		p = strings.TrimPrefix(p, base.Path)
+	}
+
+	slug := strings.TrimPrefix(p, "/")
+	slug = strings.TrimSuffix(slug, "/index.html")
+	slug = strings.TrimSuffix(slug, ".html")
+	slug = strings.TrimSuffix(slug, "/")
+	if slug == "" || slug == "index" {
+		return "", false
+	}
+	return slug, true
+}
+
+// checkInternalLinks returns an error listing every internal link that does
+// not resolve to a note in notes
+func checkInternalLinks(notes []Note, baseURL string) error {
+	slugs := make(map[string]bool, len(notes))
+	for _, note := range notes {
+		slugs[note.Slug] = true
+	}
+
+	var broken []string
+	for _, note := range notes {
+		for _, link := range note.Links {
+			slug, ok := internalSlug(link.URL, baseURL)
+			if ok && !slugs[slug] {
+				broken = append(broken, fmt.Sprintf("%s links to unknown note %q (%s)", note.Slug, slug, link.URL))
+			}
+		}
+	}
+
+	if len(broken) > 0 {
+		return fmt.Errorf("broken internal links:\n  %s", strings.Join(broken, "\n  "))
+	}
+	return nil
+}
+
+// noteLinkURLs returns every URL note links to, from its links list followed
+// by the links inline in its rendered Markdown fields
+func noteLinkURLs(note Note) []string {
+	var urls []string
+	for _, link := range note.Links {
+		urls = append(urls, link.URL)
+	}
+
+	fields := append([]template.HTML{note.ThesisHTML, note.ExampleHTML, note.BodyHTML}, note.BulletsHTML...)
+	for _, footnote := range note.FootnotesHTML {
+		fields = append(fields, footnote.HTML)
+	}
+	for _, field := range fields {
+		for _, match := range hrefAttr.FindAllStringSubmatch(string(field), -1) {
+			urls = append(urls, html.UnescapeString(match[1]))
+		}
+	}
+	return urls
+}
+
+// noteBacklinks maps each slug to the notes that link to it, in the order of
+// notes. A note is listed at most once per target and never for itself.
+func noteBacklinks(notes []Note, baseURL string) map[string][]Note {
+	backlinks := make(map[string][]Note)
+	for _, note := range notes {
+		seen := map[string]bool{note.Slug: true}
+		for _, link := range noteLinkURLs(note) {
+			slug, ok := internalSlug(link, baseURL)
+			if !ok || seen[slug] {
+				continue
+			}
+			seen[slug] = true
+			backlinks[slug] = append(backlinks[slug], note)
+		}
+	}
+	return backlinks
+}
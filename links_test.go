@@ -0,0 +1,100 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInternalSlug(t *testing.T) {
+	tests := []struct {
+		link     string
+		wantSlug string
+		wantOK   bool
+	}{
+		{"/poc-vs-mvp/", "poc-vs-mvp", true},
+		{"/poc-vs-mvp.html", "poc-vs-mvp", true},
+		{"poc-vs-mvp.html", "poc-vs-mvp", true},
+		{"/poc-vs-mvp/index.html#section", "poc-vs-mvp", true},
+		{"https://notes.example.com/poc-vs-mvp/", "poc-vs-mvp", true},
+		{"https://example.org/poc-vs-mvp/", "", false},
+		{"mailto:someone@example.com", "", false},
+		{"/", "", false},
+		{"/index.html", "", false},
+		{"/reuse-index.html", "reuse-index", true},
+		{"/go/concurrency/", "go/concurrency", true},
+	}
+
+	for _, tt := range tests {
+		slug, ok := internalSlug(tt.link, "https://notes.example.com")
+		if slug != tt.wantSlug || ok != tt.wantOK {
+			t.Errorf("internalSlug(%q) = (%q, %v), want (%q, %v)", tt.link, slug, ok, tt.wantSlug, tt.wantOK)
+		}
+	}
+}
+
+func TestCheckInternalLinks(t *testing.T) {
+	notes := []Note{
+		{Slug: "a", Links: []Link{{Label: "B", URL: "/b/"}, {Label: "External", URL: "https://example.org/missing/"}}},
+		{Slug: "b", Links: []Link{{Label: "Typo", URL: "/aa/"}}},
+	}
+
+	err := checkInternalLinks(notes, "https://notes.example.com")
+	if err == nil {
+		t.Fatal("expected an error for a broken internal link, got nil")
+	}
+	if !strings.Contains(err.Error(), `"aa"`) {
+		t.Errorf("error %q should name the broken slug", err)
+	}
+	if strings.Contains(err.Error(), "missing") {
+		t.Errorf("error %q should ignore external links", err)
+	}
+
+	notes[1].Links = nil
+	if err := checkInternalLinks(notes, "https://notes.example.com"); err != nil {
+		t.Errorf("expected no error when all internal links resolve, got %v", err)
+	}
+}
+
+func TestInternalSlugWithBasePath(t *testing.T) {
+	tests := []struct {
+		link     string
+		wantSlug string
+		wantOK   bool
+	}{
+		{"/notes/poc-vs-mvp/", "poc-vs-mvp", true},
+		{"/poc-vs-mvp/", "poc-vs-mvp", true},
+		{"https://example.com/notes/poc-vs-mvp/", "poc-vs-mvp", true},
+		{"/notes/", "", false},
+	}
+
+	for _, tt := range tests {
+		slug, ok := internalSlug(tt.link, "https://example.com/notes")
+		if slug != tt.wantSlug || ok != tt.wantOK {
+			t.Errorf("internalSlug(%q) = (%q, %v), want (%q, %v)", tt.link, slug, ok, tt.wantSlug, tt.wantOK)
+		}
+	}
+}
+
+func TestNoteBacklinks(t *testing.T) {
+	notes := []Note{
+		{Slug: "a", Title: "A"},
+		{Slug: "b", Title: "B", Links: []Link{{Label: "A", URL: "/a/"}, {Label: "Again", URL: "https://notes.example.com/a.html"}}},
+		{Slug: "c", Title: "C", ExampleHTML: `<p>See <a href="/a/">A</a> and <a href="https://example.org/a/">elsewhere</a>.</p>`},
+		{Slug: "d", Title: "D", Links: []Link{{Label: "Self", URL: "/d/"}, {Label: "External", URL: "https://example.org/b/"}}},
+	}
+
+	backlinks := noteBacklinks(notes, "https://notes.example.com")
+
+	var got []string
+	for _, note := range backlinks["a"] {
+		got = append(got, note.Slug)
+	}
+	if strings.Join(got, ",") != "b,c" {
+		t.Errorf("backlinks for a = %v, want [b c]", got)
+	}
+	for _, slug := range []string{"b", "c", "d"} {
+		if len(backlinks[slug]) != 0 {
+			t.Errorf("expected no backlinks for %s, got %d", slug, len(backlinks[slug]))
+		}
+	}
+}
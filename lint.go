@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// lintContent runs the build's validations over every content file in fsys
+// without writing any output. Unlike a build, it keeps going after a problem
+// and returns every one it finds. Drafts and notes outside their publish
+// window at cfg.BuildTime are validated on their own but, as in the build,
+// left out of the checks across notes; drafts are included when
+// INCLUDE_DRAFTS=1 is set.
+func lintContent(cfg Config, fsys fs.FS) ([]error, int) {
+	var problems []error
+	var notes []Note
+	files := 0
+	includeDrafts := os.Getenv("INCLUDE_DRAFTS") == "1"
+	embedPolicy := newEmbedPolicy(cfg.EmbedHosts)
+
+	err := fs.WalkDir(fsys, "content", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || noteDecoders[filepath.Ext(path)] == nil {
+			return nil
+		}
+
+		note, err := decodeNote(cfg, fsys, path)
+		if errors.Is(err, errNoFrontMatter) {
+			return nil
+		}
+		files++
+		if err != nil {
+			problems = append(problems, err)
+			return nil
+		}
+		if err := prepareNote(cfg, &note, embedPolicy); err != nil {
+			problems = append(problems, err)
+			return nil
+		}
+		if (!note.Draft || includeDrafts) && scheduled(note, cfg.BuildTime) {
+			notes = append(notes, note)
+		}
+		return nil
+	})
+	if err != nil {
+		return append(problems, err), files
+	}
+
+	checks := []func() error{
+		func() error { return checkDuplicateSlugs(notes) },
+		func() error { return checkNoteIDs(notes) },
+		func() error { return checkAliases(notes) },
+		func() error { return checkTranslations(notes) },
+		func() error { return checkSeries(notes) },
+		func() error { return checkCategories(notes, cfg.RequireCategory) },
+		func() error { return checkTags(notes) },
+		func() error {
+			roots, err := staticRoots(cfg, fsys)
+			if err != nil {
+				return fmt.Errorf("reading static files: %w", err)
+			}
+			themes, err := availableThemes(roots)
+			if err != nil {
+				return fmt.Errorf("reading themes: %w", err)
+			}
+			return checkThemes(notes, themes)
+		},
+		func() error { return checkInternalLinks(notes, cfg.BaseURL) },
+	}
+	for _, check := range checks {
+		if err := check(); err != nil {
+			problems = append(problems, err)
+		}
+	}
+	return problems, files
+}
+
+// runLint implements the lint subcommand, which prints one line per content
+// problem and fails when there are any
+func runLint(args []string) error {
+	flags := flag.NewFlagSet("lint", flag.ExitOnError)
+	configPath := flags.String("config", defaultConfigPath, "path to the site configuration file")
+	requireCategory := flags.Bool("require-category", false, "report notes without a category")
+	restrictTags := flags.Bool("restrict-tags", false, "report tags not listed in the tags config")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	// Lint never writes URLs, so it does not need base_url to be set
+	cfg, err := readConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	if *requireCategory {
+		cfg.RequireCategory = true
+	}
+	if *restrictTags {
+		cfg.RestrictTags = true
+	}
+	if cfg.BuildTime, _, err = buildTime(); err != nil {
+		return err
+	}
+
+	problems, files := lintContent(cfg, os.DirFS("."))
+	for _, problem := range problems {
+		// Keep each problem on one line so the report can be grepped
+		fmt.Println(strings.ReplaceAll(problem.Error(), "\n", " "))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("found %d problems in %d content files", len(problems), files)
+	}
+	fmt.Printf("✓ No problems in %d content files\n", files)
+	return nil
+}
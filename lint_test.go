@@ -0,0 +1,38 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLintContentReportsEveryProblem(t *testing.T) {
+	fsys := fstest.MapFS{
+		"static/style.css":      {Data: []byte(".note-detail.blue {}")},
+		"content/good.yaml":     {Data: []byte("slug: good\ntitle: Good\nthesis: Good\nlinks:\n  - {label: Missing, url: /missing/}\n")},
+		"content/untitled.yaml": {Data: []byte("slug: untitled\nthesis: No title\n")},
+		"content/dated.yaml":    {Data: []byte("slug: dated\ntitle: Dated\nthesis: Bad date\ndate: 2024-13-01\n")},
+		"content/pink.yaml":     {Data: []byte("slug: pink\ntitle: Pink\nthesis: Unknown theme\ntheme: pink\n")},
+		"content/README.md":     {Data: []byte("# Not a note\n")},
+	}
+
+	problems, files := lintContent(testConfig(t), fsys)
+	if files != 4 {
+		t.Errorf("expected 4 content files, got %d", files)
+	}
+	if len(problems) != 4 {
+		t.Fatalf("expected 4 problems, got %d: %v", len(problems), problems)
+	}
+	for i, want := range []string{"content/dated.yaml", "content/untitled.yaml", "pink", "/missing/"} {
+		if !strings.Contains(problems[i].Error(), want) {
+			t.Errorf("problem %d = %q, want it to mention %s", i, problems[i], want)
+		}
+	}
+}
+
+func TestLintContentClean(t *testing.T) {
+	cfg := testConfig(t)
+	if problems, _ := lintContent(cfg, siteFS); len(problems) > 0 {
+		t.Errorf("expected the site content to lint cleanly, got %v", problems)
+	}
+}
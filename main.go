@@ -2,287 +2,1403 @@ package main
 
 import (
 	"embed"
-	"encoding/xml"
+	"errors"
+	"flag"
 	"fmt"
 	"html/template"
 	"io"
+	"io/fs"
+	"math"
+	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
-	"gopkg.in/yaml.v3"
+	"github.com/microcosm-cc/bluemonday"
 )
 
-//go:embed templates/*
-var templatesFS embed.FS
-
-//go:embed static/*
-var staticFS embed.FS
-
-//go:embed content/*
-var notesFS embed.FS
+// siteFS holds the content, templates, and static files built into the
+// binary. Notes may be organized into nested subdirectories of content.
+//
+//go:embed content templates static
+var siteFS embed.FS
 
 // Link represents a link with label and URL
 type Link struct {
-	Label string `yaml:"label"`
-	URL   string `yaml:"url"`
+	Label string `yaml:"label" json:"label" toml:"label"`
+	URL   string `yaml:"url" json:"url" toml:"url"`
 }
 
-// Note represents a single note from YAML
+// Note represents a single note read from a YAML, JSON, or TOML content file
 type Note struct {
-	Slug    string   `yaml:"slug"`
-	Title   string   `yaml:"title"`
-	Thesis  string   `yaml:"thesis"`
-	Quote   string   `yaml:"quote"`
-	Bullets []string `yaml:"bullets"`
-	Example string   `yaml:"example"`
-	Diagram string   `yaml:"diagram"`
-	Links   []Link   `yaml:"links"`
-	Tags    []string `yaml:"tags"`
-	Theme   string   `yaml:"theme"`
+	Slug        string   `yaml:"slug" json:"slug" toml:"slug"`
+	ID          string   `yaml:"id" json:"id" toml:"id"`
+	Title       string   `yaml:"title" json:"title" toml:"title"`
+	Thesis      string   `yaml:"thesis" json:"thesis" toml:"thesis"`
+	Quote       string   `yaml:"quote" json:"quote" toml:"quote"`
+	Bullets     []string `yaml:"bullets" json:"bullets" toml:"bullets"`
+	Example     string   `yaml:"example" json:"example" toml:"example"`
+	ExampleLang string   `yaml:"example_lang" json:"example_lang" toml:"example_lang"`
+	Diagram     string   `yaml:"diagram" json:"diagram" toml:"diagram"`
+	DiagramType string   `yaml:"diagram_type" json:"diagram_type" toml:"diagram_type"`
+	Links       []Link   `yaml:"links" json:"links" toml:"links"`
+	Tags        []string `yaml:"tags" json:"tags" toml:"tags"`
+	Theme       string   `yaml:"theme" json:"theme" toml:"theme"`
+	Date        string   `yaml:"date" json:"date" toml:"date"`
+	Updated     string   `yaml:"updated" json:"updated" toml:"updated"`
+	PublishAt   string   `yaml:"publish_at" json:"publish_at" toml:"publish_at"`
+	ExpireAt    string   `yaml:"expire_at" json:"expire_at" toml:"expire_at"`
+	Draft       bool     `yaml:"draft" json:"draft" toml:"draft"`
+	Order       int      `yaml:"order" json:"order" toml:"order"`
+	Author      string   `yaml:"author" json:"author" toml:"author"`
+	Excerpt     string   `yaml:"excerpt" json:"excerpt" toml:"excerpt"`
+	Description string   `yaml:"description" json:"description" toml:"description"`
+	ChangeFreq  string   `yaml:"changefreq" json:"changefreq" toml:"changefreq"`
+	Priority    string   `yaml:"priority" json:"priority" toml:"priority"`
+	Image       string   `yaml:"image" json:"image" toml:"image"`
+	ImageAlt    string   `yaml:"image_alt" json:"image_alt" toml:"image_alt"`
+	TOC         bool     `yaml:"toc" json:"toc" toml:"toc"`
+	Aliases     []string `yaml:"aliases" json:"aliases" toml:"aliases"`
+	Series      string   `yaml:"series" json:"series" toml:"series"`
+	SeriesOrder int      `yaml:"series_order" json:"series_order" toml:"series_order"`
+	Lang        string   `yaml:"lang" json:"lang" toml:"lang"`
+	NoIndex     bool     `yaml:"noindex" json:"noindex" toml:"noindex"`
+	HTML        string   `yaml:"html" json:"html" toml:"html"`
+	HeadExtra   string   `yaml:"head_extra" json:"head_extra" toml:"head_extra"`
+	Category    string   `yaml:"category" json:"category" toml:"category"`
+	Math        bool     `yaml:"math" json:"math" toml:"math"`
+	Featured    bool     `yaml:"featured" json:"featured" toml:"featured"`
+	Body        string   `yaml:"body" json:"body" toml:"body"`
+
+	// Translations maps a language code to the slug of this note's
+	// translation into that language
+	Translations map[string]string `yaml:"translations" json:"translations" toml:"translations"`
+
+	// Footnotes maps each label referenced as [^label] in the thesis,
+	// bullets, or example to its Markdown text
+	Footnotes map[string]string `yaml:"footnotes" json:"footnotes" toml:"footnotes"`
+
+	// Source is the content file the note was read from
+	Source string `yaml:"-" json:"-" toml:"-"`
+
+	// ReadingTime is the estimated reading time in minutes
+	ReadingTime int `yaml:"-" json:"-" toml:"-"`
+
+	// Rendered Markdown, populated after parsing
+	ThesisHTML  template.HTML   `yaml:"-" json:"-" toml:"-"`
+	BulletsHTML []template.HTML `yaml:"-" json:"-" toml:"-"`
+	ExampleHTML template.HTML   `yaml:"-" json:"-" toml:"-"`
+	BodyHTML    template.HTML   `yaml:"-" json:"-" toml:"-"`
+
+	// EmbedHTML is the html field after sanitizing
+	EmbedHTML template.HTML `yaml:"-" json:"-" toml:"-"`
+
+	// HeadExtraHTML is the head_extra field after validation
+	HeadExtraHTML template.HTML `yaml:"-" json:"-" toml:"-"`
+
+	// GitModified is the last commit time of the content file, set by the
+	// build when sitemap lastmod dates are read from git
+	GitModified string `yaml:"-" json:"-" toml:"-"`
+
+	// ImageSrcset lists resized variants of the image, set by the build when
+	// image resizing is enabled
+	ImageSrcset string `yaml:"-" json:"-" toml:"-"`
+
+	// FootnotesHTML lists the rendered footnotes in order of first reference
+	FootnotesHTML []Footnote `yaml:"-" json:"-" toml:"-"`
+}
+
+// dateLayout is the format used for note dates
+const dateLayout = "2006-01-02"
+
+// displayDateLayout is the format used to show note dates on pages
+const displayDateLayout = "January 2, 2006"
+
+// FooterData holds data for the footer partial shared by all pages
+type FooterData struct {
+	Year        int
+	SiteTitle   string
+	LastUpdated string
+}
+
+// newFooterData returns the footer data for the current build
+func newFooterData(cfg Config) FooterData {
+	footer := FooterData{
+		Year:      cfg.BuildTime.Year(),
+		SiteTitle: cfg.SiteTitle,
+	}
+	if !cfg.LastUpdated.IsZero() {
+		footer.LastUpdated = cfg.LastUpdated.Format(dateLayout)
+	}
+	return footer
+}
+
+// NotePageData holds data for the note template
+type NotePageData struct {
+	Note         Note
+	Lang         string
+	CSP          string
+	PWA          bool
+	ThemeColor   string
+	CanonicalURL string
+	Description  string
+	JSONLD       template.JS
+	DiagramURL   string
+	ImageURL     string
+	Prev         *Note
+	Next         *Note
+	SeriesURL    string
+	SeriesPrev   *Note
+	SeriesNext   *Note
+	CategoryURL  string
+	Alternates   []Alternate
+	Related      []Note
+	Backlinks    []Note
+	Breadcrumbs  []Breadcrumb
+	TOC          []TOCEntry
+	Anchors      map[string]string
+	Footer       FooterData
+}
+
+// maxDescriptionLength is the maximum length of a note description in
+// characters, whether written or derived from the thesis
+const maxDescriptionLength = 160
+
+// excerptLength is the maximum length of an excerpt derived from the thesis
+const excerptLength = 160
+
+// SiteData describes the site as a whole for pages that represent it rather
+// than a single note
+type SiteData struct {
+	Title       string
+	Description string
+	URL         string
+}
+
+// newSiteData returns the site data from cfg
+func newSiteData(cfg Config) SiteData {
+	return SiteData{
+		Title:       cfg.SiteTitle,
+		Description: cfg.Description,
+		URL:         cfg.BaseURL + "/",
+	}
 }
 
 // IndexData holds data for the index template
 type IndexData struct {
-	Notes []Note
+	Lang         string
+	CSP          string
+	PWA          bool
+	ThemeColor   string
+	Site         SiteData
+	CanonicalURL string
+	Featured     []Note
+	Notes        []Note
+	NoteCount    int
+	Tags         []TagCount
+	Pagination   Pagination
+	Footer       FooterData
 }
 
-// Sitemap represents the root sitemap element
-type Sitemap struct {
-	XMLName xml.Name     `xml:"urlset"`
-	XMLNS   string       `xml:"xmlns,attr"`
-	URLs    []SitemapURL `xml:"url"`
+// NotFoundData holds data for the 404 template
+type NotFoundData struct {
+	Lang   string
+	CSP    string
+	Notes  []Note
+	Footer FooterData
 }
 
-// SitemapURL represents a URL in the sitemap
-type SitemapURL struct {
-	Loc        string `xml:"loc"`
-	LastMod    string `xml:"lastmod"`
-	ChangeFreq string `xml:"changefreq"`
-	Priority   string `xml:"priority"`
-}
+// notFoundNoteCount is the number of recent notes suggested on the 404 page
+const notFoundNoteCount = 3
 
 func main() {
-	if err := run(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+	// Subcommands are handled before the build flags are parsed
+	if len(os.Args) > 1 {
+		subcommands := map[string]func([]string) error{"new": runNew, "lint": runLint}
+		if subcommand, ok := subcommands[os.Args[1]]; ok {
+			if err := subcommand(os.Args[2:]); err != nil {
+				exitWithError(err)
+			}
+			return
+		}
+	}
+
+	configPath := flag.String("config", defaultConfigPath, "path to the site configuration file")
+	outDir := flag.String("out", "", "directory to write the generated site to (overrides output_dir)")
+	watch := flag.Bool("watch", false, "rebuild from the files on disk whenever content, templates, or static change")
+	serve := flag.Bool("serve", false, "serve the output directory over HTTP after building")
+	port := flag.Int("port", 8080, "port for the -serve preview server")
+	compress := flag.Bool("compress", false, "write precompressed .gz copies of text output files")
+	pwa := flag.Bool("pwa", false, "make the site installable with a web app manifest and offline service worker")
+	withBrotli := flag.Bool("brotli", false, "with -compress, also write precompressed .br copies")
+	redirects := flag.String("redirects", "", "also write alias redirects for a host; supported: netlify")
+	headers := flag.String("headers", "", "also write cache and security headers for a host; supported: netlify")
+	lastMod := flag.String("lastmod", "", "source of sitemap lastmod dates for notes; supported: git")
+	checkLinks := flag.Bool("check-links", false, "check that external link URLs are reachable and warn about failures")
+	requireCategory := flag.Bool("require-category", false, "fail the build when a note has no category")
+	images := flag.Bool("images", false, "write resized variants of cover images and list them in srcset attributes")
+	incremental := flag.Bool("incremental", false, "keep the output directory and skip note pages whose inputs are unchanged")
+	strict := flag.Bool("strict", false, "fail the build when any warning is reported")
+	restrictTags := flag.Bool("restrict-tags", false, "fail the build when a note has a tag not listed in the tags config")
+	flag.Parse()
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		exitWithError(err)
+	}
+	if *outDir != "" {
+		cfg.OutputDir = *outDir
+	}
+	if *redirects != "" {
+		if err := validateRedirects(*redirects); err != nil {
+			exitWithError(err)
+		}
+		cfg.Redirects = *redirects
+	}
+	if *headers != "" {
+		if err := validateHeaders(*headers); err != nil {
+			exitWithError(err)
+		}
+		cfg.Headers = *headers
+	}
+	if *lastMod != "" {
+		if err := validateLastMod(*lastMod); err != nil {
+			exitWithError(err)
+		}
+		cfg.LastMod = *lastMod
+	}
+	if *checkLinks {
+		cfg.CheckLinks = true
+	}
+	if *compress {
+		cfg.Compress = true
+	}
+	if *pwa {
+		cfg.PWA = true
+	}
+	if *withBrotli {
+		cfg.Brotli = true
+	}
+	if *requireCategory {
+		cfg.RequireCategory = true
+	}
+	if *incremental {
+		cfg.Incremental = true
+	}
+	if *images {
+		cfg.Images = true
+	}
+	if *strict {
+		cfg.Strict = true
+	}
+	if *restrictTags {
+		cfg.RestrictTags = true
+	}
+
+	switch {
+	case *watch && *serve:
+		errs := make(chan error, 2)
+		go func() { errs <- servePreview(cfg.OutputDir, cfg.BasePath, *port) }()
+		go func() { errs <- watchAndRebuild(cfg) }()
+		err = <-errs
+	case *watch:
+		err = watchAndRebuild(cfg)
+	case *serve:
+		if err = run(cfg, siteFS); err == nil {
+			err = servePreview(cfg.OutputDir, cfg.BasePath, *port)
+		}
+	default:
+		err = run(cfg, siteFS)
+	}
+	if err != nil {
+		exitWithError(err)
 	}
 }
 
-func run() error {
+func exitWithError(err error) {
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	os.Exit(1)
+}
+
+// run builds the site from fsys, which must contain the content, templates,
+// and static directories, writing the result to the configured output directory
+func run(cfg Config, fsys fs.FS) error {
+	outDir := cfg.OutputDir
+	buildStart := time.Now()
+	stamp, reproducible, err := buildTime()
+	if err != nil {
+		return err
+	}
+	cfg.BuildTime = stamp
+	manifest := BuildManifest{BuildTime: stamp.UTC().Format(time.RFC3339)}
+	var phases PhaseDurations
+	warnings := newReporter(cfg.Strict)
+
 	// Read all notes
-	notes, err := readNotes()
+	phaseStart := time.Now()
+	notes, err := readNotes(cfg, fsys)
 	if err != nil {
 		return fmt.Errorf("reading notes: %w", err)
 	}
+	cfg.LastUpdated = siteLastUpdated(notes, cfg.BuildTime)
 
-	// Sort notes by slug for consistent ordering
-	sort.Slice(notes, func(i, j int) bool {
-		return notes[i].Slug < notes[j].Slug
-	})
+	// Ensure no two notes would write to the same output path
+	if err := checkDuplicateSlugs(notes); err != nil {
+		return err
+	}
 
-	// Clean and recreate output directory
-	if err := os.RemoveAll("output"); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("removing output directory: %w", err)
+	// Ensure every note ID identifies a single note in feeds
+	if err := checkNoteIDs(notes); err != nil {
+		return err
+	}
+
+	// Ensure aliases don't overwrite notes or each other
+	if err := checkAliases(notes); err != nil {
+		return err
+	}
+
+	// Ensure translations point at existing notes
+	if err := checkTranslations(notes); err != nil {
+		return err
+	}
+
+	// Ensure each series has a unique path and distinct part numbers
+	if err := checkSeries(notes); err != nil {
+		return err
+	}
+
+	// Ensure each category has a unique path, and that every note has one
+	// when categories are required
+	if err := checkCategories(notes, cfg.RequireCategory); err != nil {
+		return err
+	}
+
+	// Ensure every tag has a unique page path
+	if err := checkTags(notes); err != nil {
+		return err
+	}
+
+	// Ensure every theme has styles
+	roots, err := staticRoots(cfg, fsys)
+	if err != nil {
+		return fmt.Errorf("reading static files: %w", err)
+	}
+	themes, err := availableThemes(roots)
+	if err != nil {
+		return fmt.Errorf("reading themes: %w", err)
+	}
+	if err := checkThemes(notes, themes); err != nil {
+		return err
+	}
+
+	// Ensure links between notes resolve
+	if err := checkInternalLinks(notes, cfg.BaseURL); err != nil {
+		return err
+	}
+
+	// Optionally warn about external links that are unreachable
+	if cfg.CheckLinks {
+		client := &http.Client{Timeout: linkCheckTimeout}
+		for _, w := range checkExternalLinks(client, externalLinks(notes, cfg.BaseURL), linkCheckConcurrency) {
+			warnings.Warnf("unreachable link %s", w)
+		}
+	}
+
+	// Optionally date notes by their last commit for the sitemap
+	if cfg.LastMod == lastModGit {
+		setGitLastModified(notes, warnings)
+	}
+
+	// Sort notes by order and slug for consistent ordering
+	sortNotes(notes)
+	phases.Read = durationMillis(time.Since(phaseStart))
+
+	// Clean and recreate output directory, keeping the previous build's note
+	// pages for incremental builds
+	if cfg.Incremental {
+		if err := pruneOutput(cfg, notes); err != nil {
+			return fmt.Errorf("pruning output directory: %w", err)
+		}
+	} else {
+		if err := os.RemoveAll(outDir); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("removing output directory: %w", err)
+		}
 	}
-	if err := os.MkdirAll("output", 0755); err != nil {
+	if err := os.MkdirAll(outDir, 0755); err != nil {
 		return fmt.Errorf("creating output directory: %w", err)
 	}
 
+	// Copy static files, fingerprinting assets for cache busting
+	phaseStart = time.Now()
+	assets, err := copyStaticFiles(outDir, roots, warnings)
+	if err != nil {
+		return fmt.Errorf("copying static files: %w", err)
+	}
+	if err := writeAssetManifest(outDir, assets); err != nil {
+		return fmt.Errorf("writing asset manifest: %w", err)
+	}
+	if hasHighlightedExamples(notes) {
+		if err := generateHighlightCSS(outDir); err != nil {
+			return fmt.Errorf("writing %s: %w", highlightCSSFile, err)
+		}
+	}
+	imageVariants := 0
+	if cfg.Images {
+		// Resize the copies in the output so images from static_dirs are found
+		if imageVariants, err = generateImageVariants(cfg, os.DirFS(outDir), notes); err != nil {
+			return err
+		}
+	}
+	phases.Static = durationMillis(time.Since(phaseStart))
+
 	// Parse templates
-	indexTmpl, err := template.ParseFS(templatesFS, "templates/index.html", "templates/footer.html")
+	phaseStart = time.Now()
+	funcs := templateFuncs(cfg, assets)
+	indexTmpl, err := parseTemplate(fsys, funcs, "templates/index.html", "templates/footer.html")
 	if err != nil {
 		return fmt.Errorf("parsing index template: %w", err)
 	}
 
-	noteTmpl, err := template.ParseFS(templatesFS, "templates/note.html", "templates/footer.html")
+	noteTmpls, err := parseNoteTemplates(fsys, funcs)
 	if err != nil {
 		return fmt.Errorf("parsing note template: %w", err)
 	}
 
+	notFoundTmpl, err := parseTemplate(fsys, funcs, "templates/404.html", "templates/footer.html")
+	if err != nil {
+		return fmt.Errorf("parsing 404 template: %w", err)
+	}
+
 	// Generate index page
-	if err := generateIndex(indexTmpl, notes); err != nil {
+	if err := generateIndex(cfg, indexTmpl, notes); err != nil {
 		return fmt.Errorf("generating index: %w", err)
 	}
 
-	// Generate individual note pages
-	for _, note := range notes {
-		if err := generateNotePage(noteTmpl, note); err != nil {
-			return fmt.Errorf("generating note page for %s: %w", note.Slug, err)
+	// Generate individual note pages, skipping unchanged ones when incremental
+	var cache *buildCache
+	if cfg.Incremental {
+		if cache, err = loadBuildCache(cfg, fsys, assets); err != nil {
+			return fmt.Errorf("loading build cache: %w", err)
+		}
+	}
+	series := buildSeries(notes)
+	if err := generateNotePages(cfg, noteTmpls, notes, series, cache); err != nil {
+		return err
+	}
+	if cache != nil {
+		if err := cache.save(); err != nil {
+			return fmt.Errorf("writing build cache: %w", err)
 		}
 	}
 
-	// Copy static files
-	if err := copyStaticFiles(); err != nil {
-		return fmt.Errorf("copying static files: %w", err)
+	seriesTmpl, err := parseTemplate(fsys, funcs, "templates/series.html", "templates/footer.html")
+	if err != nil {
+		return fmt.Errorf("parsing series template: %w", err)
+	}
+
+	// Generate a page for each series listing its parts
+	if err := generateSeriesPages(cfg, seriesTmpl, series); err != nil {
+		return err
+	}
+
+	categoryTmpl, err := parseTemplate(fsys, funcs, "templates/category.html", "templates/footer.html")
+	if err != nil {
+		return fmt.Errorf("parsing category template: %w", err)
+	}
+
+	// Generate a page for each category listing its notes
+	categories := buildCategories(notes)
+	if err := generateCategoryPages(cfg, categoryTmpl, categories); err != nil {
+		return err
+	}
+
+	tagTmpl, err := parseTemplate(fsys, funcs, "templates/tag.html", "templates/footer.html")
+	if err != nil {
+		return fmt.Errorf("parsing tag template: %w", err)
+	}
+
+	// Generate a page and feed for each tag
+	if err := generateTagPages(cfg, tagTmpl, notes); err != nil {
+		return err
+	}
+
+	archiveTmpl, err := parseTemplate(fsys, funcs, "templates/archive.html", "templates/footer.html")
+	if err != nil {
+		return fmt.Errorf("parsing archive template: %w", err)
+	}
+
+	// Generate alphabetical archive page
+	if err := generateArchive(cfg, archiveTmpl, notes); err != nil {
+		return fmt.Errorf("generating archive: %w", err)
+	}
+
+	allTmpl, err := parseTemplate(fsys, funcs, "templates/all.html", "templates/footer.html")
+	if err != nil {
+		return fmt.Errorf("parsing all notes template: %w", err)
+	}
+
+	// Generate the printable page with every note
+	if err := generateAllNotes(cfg, allTmpl, notes); err != nil {
+		return fmt.Errorf("generating all notes page: %w", err)
+	}
+
+	themesTmpl, err := parseTemplate(fsys, funcs, "templates/themes.html", "templates/footer.html")
+	if err != nil {
+		return fmt.Errorf("parsing themes template: %w", err)
+	}
+
+	// Generate the page grouping notes by theme
+	if err := generateThemeIndex(cfg, themesTmpl, notes); err != nil {
+		return fmt.Errorf("generating theme index: %w", err)
 	}
 
+	randomTmpl, err := parseTemplate(fsys, funcs, "templates/random.html")
+	if err != nil {
+		return fmt.Errorf("parsing random template: %w", err)
+	}
+
+	// Generate the page that redirects to a random note
+	if err := generateRandomPage(cfg, randomTmpl, notes); err != nil {
+		return fmt.Errorf("generating random page: %w", err)
+	}
+
+	redirectTmpl, err := parseTemplate(fsys, funcs, "templates/redirect.html")
+	if err != nil {
+		return fmt.Errorf("parsing redirect template: %w", err)
+	}
+
+	// Generate redirects from aliases of renamed notes
+	if err := generateAliasPages(cfg, redirectTmpl, notes); err != nil {
+		return err
+	}
+
+	// Generate 404 page
+	if err := generate404(cfg, notFoundTmpl, notes); err != nil {
+		return fmt.Errorf("generating 404 page: %w", err)
+	}
+
+	phases.Render = durationMillis(time.Since(phaseStart))
+
 	// Generate sitemap
-	if err := generateSitemap(notes); err != nil {
+	phaseStart = time.Now()
+	if err := generateSitemap(cfg, notes, sitemapMaxURLs()); err != nil {
 		return fmt.Errorf("generating sitemap: %w", err)
 	}
+	phases.Sitemap = durationMillis(time.Since(phaseStart))
+
+	// Generate robots.txt
+	if err := generateRobots(cfg); err != nil {
+		return fmt.Errorf("generating robots.txt: %w", err)
+	}
+
+	// Generate GitHub Pages hosting files
+	if err := generatePagesFiles(outDir); err != nil {
+		return fmt.Errorf("generating hosting files: %w", err)
+	}
+
+	// Generate credits and security contact files when configured
+	if cfg.Humans != nil {
+		if err := generateHumansTxt(cfg); err != nil {
+			return fmt.Errorf("generating humans.txt: %w", err)
+		}
+	}
+	if cfg.Security != nil {
+		if err := generateSecurityTxt(cfg); err != nil {
+			return fmt.Errorf("generating security.txt: %w", err)
+		}
+	}
+
+	// Generate an index of the notes for language models when enabled
+	if cfg.LLMsTxt {
+		if err := generateLLMsTxt(cfg, notes); err != nil {
+			return fmt.Errorf("generating llms.txt: %w", err)
+		}
+	}
+
+	// Generate host-specific redirects for aliases
+	if cfg.Redirects == redirectsNetlify {
+		if err := generateNetlifyRedirects(cfg, notes); err != nil {
+			return fmt.Errorf("generating _redirects: %w", err)
+		}
+	}
+
+	// Generate RSS feed
+	if err := generateRSS(cfg, notes); err != nil {
+		return fmt.Errorf("generating RSS feed: %w", err)
+	}
+
+	// Generate Atom feed
+	if err := generateAtom(cfg, notes); err != nil {
+		return fmt.Errorf("generating Atom feed: %w", err)
+	}
+
+	// Generate JSON Feed
+	if err := generateJSONFeed(cfg, notes); err != nil {
+		return fmt.Errorf("generating JSON feed: %w", err)
+	}
+
+	// List the feeds for import into feed readers
+	if err := generateOPML(cfg); err != nil {
+		return fmt.Errorf("generating OPML: %w", err)
+	}
+
+	// Generate search index
+	if err := generateSearchIndex(outDir, notes); err != nil {
+		return fmt.Errorf("generating search index: %w", err)
+	}
+
+	// Export the full note data for other frontends
+	if err := generateNotesJSON(outDir, notes); err != nil {
+		return fmt.Errorf("generating notes.json: %w", err)
+	}
+	apiNotes, err := generateNoteAPI(cfg, notes)
+	if err != nil {
+		return fmt.Errorf("generating JSON API: %w", err)
+	}
+
+	// Make the site installable; the service worker is generated last so its
+	// cache name reflects the rest of the output
+	if cfg.PWA {
+		if err := generateWebManifest(cfg); err != nil {
+			return fmt.Errorf("generating web app manifest: %w", err)
+		}
+		if err := generateServiceWorker(cfg, assets); err != nil {
+			return fmt.Errorf("generating service worker: %w", err)
+		}
+	}
+
+	// Generate host-specific cache and security headers once every page has
+	// been written
+	if cfg.Headers == headersNetlify {
+		if err := generateNetlifyHeaders(cfg, assets); err != nil {
+			return fmt.Errorf("generating _headers: %w", err)
+		}
+	}
+
+	// Write build manifest
+	manifest.NoteCount = len(notes)
+	manifest.TagCount = len(countTags(publishedNotes(notes)))
+	manifest.Content = contentStats(notes)
+	phases.Total = durationMillis(time.Since(buildStart))
 
-	fmt.Printf("✓ Generated %d note pages\n", len(notes))
+	// Timings differ on every run, so reproducible builds leave them out
+	if !reproducible {
+		manifest.Phases = &phases
+	}
+	if err := writeManifest(outDir, manifest); err != nil {
+		return fmt.Errorf("writing build manifest: %w", err)
+	}
+
+	// Precompress text output for static hosts that serve .gz/.br siblings
+	compressed := 0
+	if cfg.Compress {
+		if compressed, err = compressOutput(outDir, cfg.Brotli); err != nil {
+			return fmt.Errorf("compressing output: %w", err)
+		}
+	}
+
+	// Fail strict builds only after every warning has been printed
+	if err := warnings.Err(); err != nil {
+		return err
+	}
+
+	if cache != nil {
+		fmt.Printf("✓ Generated %d note pages (%d unchanged)\n", len(notes)-cache.Skipped, cache.Skipped)
+	} else {
+		fmt.Printf("✓ Generated %d note pages\n", len(notes))
+	}
 	fmt.Println("✓ Generated index page")
-	fmt.Println("✓ Copied static files")
+	fmt.Printf("✓ Generated %d series pages\n", len(series))
+	fmt.Printf("✓ Generated %d category pages\n", len(categories))
+	fmt.Printf("✓ Generated %d tag pages and feeds\n", len(countTags(publishedNotes(notes))))
+	fmt.Println("✓ Generated archive page")
+	fmt.Println("✓ Generated all notes page")
+	fmt.Println("✓ Generated theme index")
+	fmt.Println("✓ Generated random note page")
+	fmt.Println("✓ Generated alias redirects")
+	fmt.Println("✓ Generated 404 page")
+	fmt.Printf("✓ Copied static files (%d fingerprinted)\n", len(assets))
+	if cfg.Images {
+		fmt.Printf("✓ Generated %d resized images\n", imageVariants)
+	}
 	fmt.Println("✓ Generated sitemap.xml")
-	fmt.Println("\nBuild complete! Output is in the 'output' directory.")
+	fmt.Println("✓ Generated robots.txt")
+	fmt.Println("✓ Generated hosting files")
+	if cfg.Humans != nil {
+		fmt.Println("✓ Generated humans.txt")
+	}
+	if cfg.Security != nil {
+		fmt.Println("✓ Generated .well-known/security.txt")
+	}
+	if cfg.LLMsTxt {
+		fmt.Println("✓ Generated llms.txt")
+	}
+	if cfg.Redirects == redirectsNetlify {
+		fmt.Println("✓ Generated _redirects")
+	}
+	if cfg.Headers == headersNetlify {
+		fmt.Println("✓ Generated _headers")
+	}
+	fmt.Println("✓ Generated rss.xml")
+	fmt.Println("✓ Generated atom.xml")
+	fmt.Println("✓ Generated feed.json")
+	fmt.Println("✓ Generated feeds.opml")
+	fmt.Println("✓ Generated search.json")
+	fmt.Println("✓ Generated notes.json")
+	fmt.Printf("✓ Generated JSON API for %d notes\n", apiNotes)
+	fmt.Println("✓ Wrote build-manifest.json")
+	if cfg.PWA {
+		fmt.Println("✓ Generated manifest.webmanifest and sw.js")
+	}
+	if stats := manifest.Content; len(notes) > 0 {
+		fmt.Printf("✓ %d words and %d characters across %d notes (average %.1f words)\n",
+			stats.TotalWords, stats.TotalCharacters, len(notes), stats.AverageWords)
+		fmt.Printf("  Longest: %s (%d words), shortest: %s (%d words)\n",
+			stats.Longest.Slug, stats.Longest.Words, stats.Shortest.Slug, stats.Shortest.Words)
+	}
+	if cfg.Compress {
+		fmt.Printf("✓ Precompressed %d files\n", compressed)
+	}
+	fmt.Printf("\nBuild complete! Output is in the '%s' directory.\n", outDir)
 
 	return nil
 }
 
-func readNotes() ([]Note, error) {
+func readNotes(cfg Config, fsys fs.FS) ([]Note, error) {
 	var notes []Note
+	includeDrafts := os.Getenv("INCLUDE_DRAFTS") == "1"
+	embedPolicy := newEmbedPolicy(cfg.EmbedHosts)
 
-	entries, err := notesFS.ReadDir("content")
+	err := fs.WalkDir(fsys, "content", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || noteDecoders[filepath.Ext(path)] == nil {
+			return nil
+		}
+
+		note, err := decodeNote(cfg, fsys, path)
+		if errors.Is(err, errNoFrontMatter) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		// Skip drafts unless explicitly included
+		if note.Draft && !includeDrafts {
+			return nil
+		}
+
+		if err := prepareNote(cfg, &note, embedPolicy); err != nil {
+			return err
+		}
+
+		// Skip notes outside their publish window at build time
+		if !scheduled(note, cfg.BuildTime) {
+			return nil
+		}
+		notes = append(notes, note)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".yaml") {
+	return notes, nil
+}
+
+// decodeNote reads the content file at path and checks it with validateNote.
+// Markdown files without front matter return errNoFrontMatter.
+func decodeNote(cfg Config, fsys fs.FS, path string) (Note, error) {
+	var note Note
+	data, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return note, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	if err := noteDecoders[filepath.Ext(path)](data, &note); errors.Is(err, errNoFrontMatter) {
+		return note, err
+	} else if err != nil {
+		return note, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	note.Source = path
+
+	if err := validateNote(note, path, cfg.MaxLengths); err != nil {
+		return note, fmt.Errorf("%s: %w", path, err)
+	}
+	return note, nil
+}
+
+// prepareNote validates the remaining fields of a decoded note, renders its
+// Markdown, and fills in defaults from cfg
+func prepareNote(cfg Config, note *Note, embedPolicy *bluemonday.Policy) error {
+	path := note.Source
+
+	// Parse the dates if specified, storing them in the ISO layout used by
+	// every output and the schedule checks
+	for _, field := range []struct {
+		name  string
+		value *string
+	}{
+		{"date", &note.Date},
+		{"updated", &note.Updated},
+		{"publish_at", &note.PublishAt},
+		{"expire_at", &note.ExpireAt},
+	} {
+		if *field.value == "" {
 			continue
 		}
+		date, err := parseNoteDate(*field.value, cfg.DateLayout)
+		if err != nil {
+			return fmt.Errorf("parsing %s in %s: %q does not match the date layout %q", field.name, path, *field.value, cfg.DateLayout)
+		}
+		*field.value = date.Format(dateLayout)
+	}
+
+	if err := checkSchedule(*note); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	if err := validateSitemapHints(note.ChangeFreq, note.Priority); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
 
-		path := filepath.Join("content", entry.Name())
-		data, err := notesFS.ReadFile(path)
+	// Replace tag aliases so pages, counts, and feeds use canonical tags
+	tags, err := resolveTags(note.Tags, cfg.Tags, cfg.RestrictTags)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	note.Tags = tags
+
+	// Resolve how the diagram is rendered
+	if note.Diagram != "" {
+		diagramType, err := resolveDiagramType(*note)
 		if err != nil {
-			return nil, fmt.Errorf("reading %s: %w", path, err)
+			return fmt.Errorf("%s: %w", path, err)
 		}
+		note.DiagramType = diagramType
+	}
+
+	// Expand emoji shortcodes, then render Markdown fields
+	expandNoteEmoji(note)
+	if err := renderNoteMarkdown(note); err != nil {
+		return fmt.Errorf("rendering markdown in %s: %w", path, err)
+	}
+	note.EmbedHTML = renderEmbedHTML(note.HTML, embedPolicy)
+	note.HeadExtraHTML = template.HTML(strings.TrimSpace(note.HeadExtra))
+
+	// Set default theme if not specified
+	if note.Theme == "" {
+		note.Theme = defaultTheme
+	}
+
+	// Derive an excerpt for index cards if not specified
+	if note.Excerpt == "" {
+		note.Excerpt = summarize(note.Thesis, excerptLength)
+	}
+
+	// Derive a description for search results, previews, and feeds if not
+	// specified
+	if note.Description == "" {
+		note.Description = summarize(note.Thesis, maxDescriptionLength)
+	}
+
+	// Use the site language if not specified
+	if note.Lang == "" {
+		note.Lang = cfg.DefaultLang
+	}
+
+	// Attribute the note to the site author if not specified
+	if note.Author == "" {
+		note.Author = cfg.Author
+	}
+
+	note.ReadingTime = readingTime(noteWordCount(*note))
+	return nil
+}
+
+// wordsPerMinute is the assumed reading speed for reading time estimates
+const wordsPerMinute = 200
+
+// noteWordCount returns the number of words in the thesis, bullets, example, and body of note
+func noteWordCount(note Note) int {
+	count := len(strings.Fields(note.Thesis)) + len(strings.Fields(note.Example)) + len(strings.Fields(note.Body))
+	for _, bullet := range note.Bullets {
+		count += len(strings.Fields(bullet))
+	}
+	return count
+}
 
-		var note Note
-		if err := yaml.Unmarshal(data, &note); err != nil {
-			return nil, fmt.Errorf("parsing %s: %w", path, err)
+// noteCharCount returns the number of characters in the thesis, bullets, example, and body of note
+func noteCharCount(note Note) int {
+	count := utf8.RuneCountInString(note.Thesis) + utf8.RuneCountInString(note.Example) + utf8.RuneCountInString(note.Body)
+	for _, bullet := range note.Bullets {
+		count += utf8.RuneCountInString(bullet)
+	}
+	return count
+}
+
+// readingTime returns the estimated minutes to read words, rounded up to at least one
+func readingTime(words int) int {
+	minutes := (words + wordsPerMinute - 1) / wordsPerMinute
+	if minutes < 1 {
+		return 1
+	}
+	return minutes
+}
+
+// unorderedWeight is the sort weight of notes without an explicit order so
+// they sink below ordered notes
+const unorderedWeight = math.MaxInt
+
+// sortWeight returns the weight used to order a note; an order of zero means unset
+func sortWeight(note Note) int {
+	if note.Order == 0 {
+		return unorderedWeight
+	}
+	return note.Order
+}
+
+// sortNotes sorts notes by their order field, then by slug as a tiebreaker
+func sortNotes(notes []Note) {
+	sort.Slice(notes, func(i, j int) bool {
+		wi, wj := sortWeight(notes[i]), sortWeight(notes[j])
+		if wi != wj {
+			return wi < wj
 		}
+		return notes[i].Slug < notes[j].Slug
+	})
+}
+
+// reservedSlugRoots are the top-level directories of generated pages, which
+// no slug may start with
+var reservedSlugRoots = map[string]bool{
+	"all": true, "api": true, "archive": true, "category": true, "page": true,
+	"random": true, "series": true, "tags": true, "themes": true,
+}
 
-		// Set default theme if not specified
-		if note.Theme == "" {
-			note.Theme = "default"
+// checkDuplicateSlugs returns an error naming both files when two notes share
+// a slug, and an error when a slug would overwrite a generated page
+func checkDuplicateSlugs(notes []Note) error {
+	seen := make(map[string]string, len(notes))
+	for _, note := range notes {
+		if source, ok := seen[note.Slug]; ok {
+			return fmt.Errorf("duplicate slug %q in %s and %s", note.Slug, source, note.Source)
 		}
+		seen[note.Slug] = note.Source
 
-		notes = append(notes, note)
+		if err := checkSlugPath(note.Slug); err != nil {
+			return fmt.Errorf("slug %q in %s %w", note.Slug, note.Source, err)
+		}
 	}
+	return nil
+}
 
-	return notes, nil
+// checkSlugPath returns an error when pages written under slug would
+// overwrite a generated page: when it starts with a reserved directory or has
+// an index segment, which would replace the index.html of the page above it.
+// The error completes a sentence naming the slug.
+func checkSlugPath(slug string) error {
+	segments := strings.Split(slug, "/")
+	if reservedSlugRoots[segments[0]] {
+		return fmt.Errorf("collides with the generated /%s/ pages", segments[0])
+	}
+	if slices.Contains(segments, "index") {
+		return errors.New("may not have a segment named index")
+	}
+	return nil
 }
 
-func generateIndex(tmpl *template.Template, notes []Note) error {
-	f, err := os.Create("output/index.html")
+func generateIndex(cfg Config, tmpl *template.Template, notes []Note) error {
+	tags := countTags(publishedNotes(notes))
+	site := newSiteData(cfg)
+	featured := featuredNotes(notes)
+	footer := newFooterData(cfg)
+	total := pageCount(len(notes), cfg.PageSize)
+
+	for page := 1; page <= total; page++ {
+		start := (page - 1) * cfg.PageSize
+		end := min(start+cfg.PageSize, len(notes))
+
+		dir := filepath.Join(cfg.OutputDir, filepath.FromSlash(pagePath(page)))
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+
+		data := IndexData{
+			Lang:         cfg.DefaultLang,
+			CSP:          cfg.CSP,
+			PWA:          cfg.PWA,
+			ThemeColor:   cfg.ThemeColor,
+			Site:         site,
+			CanonicalURL: cfg.BaseURL + pagePath(page),
+			Notes:        notes[start:end],
+			NoteCount:    len(notes),
+			Tags:         tags,
+			Pagination:   paginate(page, total),
+			Footer:       footer,
+		}
+		// Featured notes are highlighted on the first page only
+		if page == 1 {
+			data.Featured = featured
+		}
+		if err := writeIndexPage(tmpl, filepath.Join(dir, "index.html"), data); err != nil {
+			return fmt.Errorf("page %d: %w", page, err)
+		}
+	}
+	return nil
+}
+
+func writeIndexPage(tmpl *template.Template, path string, data IndexData) error {
+	f, err := os.Create(path)
 	if err != nil {
 		return err
 	}
 	defer f.Close()
 
-	data := IndexData{Notes: notes}
 	return tmpl.Execute(f, data)
 }
 
-func generateNotePage(tmpl *template.Template, note Note) error {
-	// Generate /slug.html
-	htmlFile := filepath.Join("output", note.Slug+".html")
-	if err := writeNoteHTML(tmpl, htmlFile, note); err != nil {
-		return err
+// generateNotePages builds the page data for each note and writes its pages
+// with the template for the note's theme. When cache is not nil, notes whose
+// inputs are unchanged since the last build are not written again.
+func generateNotePages(cfg Config, tmpls noteTemplates, notes []Note, series []Series, cache *buildCache) error {
+	baseURL := cfg.BaseURL
+	footer := newFooterData(cfg)
+	backlinks := noteBacklinks(notes, baseURL)
+	titles := make(map[string]string, len(notes))
+	for _, note := range notes {
+		titles[note.Slug] = note.Title
 	}
+	var err error
+	for i, note := range notes {
+		data := NotePageData{
+			Note:         note,
+			Lang:         note.Lang,
+			CSP:          cfg.CSP,
+			PWA:          cfg.PWA,
+			ThemeColor:   cfg.ThemeColor,
+			CanonicalURL: noteCanonicalURL(cfg, note.Slug),
+			Description:  note.Description,
+			Related:      relatedNotes(note, notes, relatedNoteCount),
+			Backlinks:    backlinks[note.Slug],
+			Breadcrumbs:  noteBreadcrumbs(cfg, note, titles),
+			Footer:       footer,
+		}
+		data.TOC, data.Anchors = noteTOC(note)
+		data.Alternates = noteAlternates(cfg, note)
+		if note.Series != "" {
+			data.SeriesURL = seriesPath(slugify(note.Series))
+			data.SeriesPrev, data.SeriesNext = seriesNeighbors(note, series)
+		}
+		if note.Category != "" {
+			data.CategoryURL = categoryPath(slugify(note.Category))
+		}
+		if note.Image != "" {
+			data.ImageURL = absoluteURL(baseURL, note.Image)
+		}
+		if data.JSONLD, err = noteJSONLD(note, data.CanonicalURL, data.Description); err != nil {
+			return fmt.Errorf("generating JSON-LD for %s: %w", note.Slug, err)
+		}
+		switch note.DiagramType {
+		case diagramImage:
+			data.DiagramURL = note.Diagram
+		case diagramPlantUML:
+			if data.DiagramURL, err = plantUMLURL(note.Diagram); err != nil {
+				return fmt.Errorf("encoding diagram for %s: %w", note.Slug, err)
+			}
+		}
+		// Link to neighbors in sort order without wrapping around
+		if i > 0 {
+			data.Prev = &notes[i-1]
+		}
+		if i < len(notes)-1 {
+			data.Next = &notes[i+1]
+		}
+		if cache != nil {
+			unchanged, err := cache.unchanged(data)
+			if err != nil {
+				return fmt.Errorf("hashing note page for %s: %w", note.Slug, err)
+			}
+			if unchanged {
+				continue
+			}
+		}
+		if err := generateNotePage(cfg, tmpls.forTheme(note.Theme), data); err != nil {
+			return fmt.Errorf("generating note page for %s: %w", note.Slug, err)
+		}
+	}
+
+	return nil
+}
 
-	// Generate /slug/index.html
-	slugDir := filepath.Join("output", note.Slug)
-	if err := os.MkdirAll(slugDir, 0755); err != nil {
+// generateNotePage writes the pages for data.Note that the URL style calls
+// for: /slug.html, /slug/index.html, or both
+func generateNotePage(cfg Config, tmpl *template.Template, data NotePageData) error {
+	for _, file := range notePageFiles(cfg, data.Note.Slug) {
+		path := filepath.Join(cfg.OutputDir, file)
+		// Create the parent directory first so nested slugs can be written
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := writeNoteHTML(tmpl, path, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generate404 writes the page served by static hosts for unknown paths,
+// suggesting a few recent notes so it isn't a dead end
+func generate404(cfg Config, tmpl *template.Template, notes []Note) error {
+	f, err := os.Create(filepath.Join(cfg.OutputDir, "404.html"))
+	if err != nil {
 		return err
 	}
-	indexFile := filepath.Join(slugDir, "index.html")
-	return writeNoteHTML(tmpl, indexFile, note)
+	defer f.Close()
+
+	recent := newestFirst(publishedNotes(notes))
+	if len(recent) > notFoundNoteCount {
+		recent = recent[:notFoundNoteCount]
+	}
+
+	return tmpl.Execute(f, NotFoundData{Lang: cfg.DefaultLang, CSP: cfg.CSP, Notes: recent, Footer: newFooterData(cfg)})
 }
 
-func writeNoteHTML(tmpl *template.Template, path string, note Note) error {
+func writeNoteHTML(tmpl *template.Template, path string, data NotePageData) error {
 	f, err := os.Create(path)
 	if err != nil {
 		return err
 	}
 	defer f.Close()
 
-	return tmpl.Execute(f, note)
+	return tmpl.Execute(f, data)
 }
 
-func copyStaticFiles() error {
-	entries, err := staticFS.ReadDir("static")
+// formatDate converts a note date into its display form, returning the
+// input unchanged when it is not a valid note date
+func formatDate(date string) string {
+	t, err := time.Parse(dateLayout, date)
 	if err != nil {
-		return err
+		return date
 	}
+	return t.Format(displayDateLayout)
+}
 
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
+// parseNoteDate parses a date as written in a content file with layout,
+// also accepting the ISO layout so scaffolded notes always parse
+func parseNoteDate(value, layout string) (time.Time, error) {
+	date, err := time.Parse(layout, value)
+	if err != nil {
+		if iso, isoErr := time.Parse(dateLayout, value); isoErr == nil {
+			return iso, nil
 		}
+	}
+	return date, err
+}
 
-		src, err := staticFS.Open(filepath.Join("static", entry.Name()))
-		if err != nil {
-			return err
-		}
-		defer src.Close()
+// parseDate parses a note date, returning the zero time when it is invalid
+func parseDate(date string) time.Time {
+	t, _ := time.Parse(dateLayout, date)
+	return t
+}
 
-		dst, err := os.Create(filepath.Join("output", entry.Name()))
-		if err != nil {
-			return err
+// relativeTime describes how long before now t was in whole calendar days,
+// such as "today", "yesterday", "3 days ago", or "2 months ago". Times after
+// now are described as today.
+func relativeTime(t, now time.Time) string {
+	day := func(t time.Time) time.Time {
+		y, m, d := t.UTC().Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	days := int(day(now).Sub(day(t)).Hours() / 24)
+
+	plural := func(n int, unit string) string {
+		if n == 1 {
+			return "1 " + unit + " ago"
 		}
-		defer dst.Close()
+		return fmt.Sprintf("%d %ss ago", n, unit)
+	}
+	switch {
+	case days <= 0:
+		return "today"
+	case days == 1:
+		return "yesterday"
+	case days < 14:
+		return plural(days, "day")
+	case days < 60:
+		return plural(days/7, "week")
+	case days < 365:
+		return plural(days/30, "month")
+	default:
+		return plural(days/365, "year")
+	}
+}
 
-		if _, err := io.Copy(dst, src); err != nil {
-			return err
+// summarize shortens s to at most max characters, cutting on a word boundary
+// and appending an ellipsis when truncated
+func summarize(s string, max int) string {
+	s = strings.TrimSpace(s)
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+
+	// Leave room for the ellipsis, then back up to the last whole word
+	cut := string(runes[:max-1])
+	if !unicode.IsSpace(runes[max-1]) {
+		if i := strings.LastIndexFunc(cut, unicode.IsSpace); i > 0 {
+			cut = cut[:i]
 		}
 	}
+	return strings.TrimRight(cut, " \t\n,.;:-") + "…"
+}
 
-	return nil
+// staticRoot is a directory of static files copied into the output
+type staticRoot struct {
+	Name string
+	FS   fs.FS
 }
 
-func generateSitemap(notes []Note) error {
-	f, err := os.Create("output/sitemap.xml")
+// staticRoots returns the static directory of fsys followed by each of the
+// configured static_dirs, in the order they are copied
+func staticRoots(cfg Config, fsys fs.FS) ([]staticRoot, error) {
+	base, err := fs.Sub(fsys, "static")
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer f.Close()
-
-	// Get base URL from environment variable with fallback
-	baseURL := os.Getenv("BASEURL")
-	if baseURL == "" {
-		return fmt.Errorf("BASEURL environment variable must be set")
+	roots := []staticRoot{{Name: "static", FS: base}}
+	for _, dir := range cfg.StaticDirs {
+		roots = append(roots, staticRoot{Name: dir, FS: os.DirFS(dir)})
 	}
-	lastMod := time.Now().Format("2006-01-02")
+	return roots, nil
+}
 
-	// Build sitemap structure
-	sitemap := Sitemap{
-		XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9",
-		URLs:  make([]SitemapURL, 0, len(notes)+1),
-	}
+// copyStaticFiles copies every file in each root to outDir, recreating the
+// directory structure and overwriting existing files. Roots are copied in
+// order, so a file in a later root replaces the same path from an earlier
+// one with a warning. CSS and JS assets are written under fingerprinted
+// names; the returned map relates each original path to its fingerprinted
+// path.
+func copyStaticFiles(outDir string, roots []staticRoot, warnings *reporter) (map[string]string, error) {
+	assets := make(map[string]string)
+	copiedFrom := make(map[string]string)
+	for _, root := range roots {
+		err := fs.WalkDir(root.FS, ".", func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
 
-	// Add homepage
-	sitemap.URLs = append(sitemap.URLs, SitemapURL{
-		Loc:        baseURL + "/",
-		LastMod:    lastMod,
-		ChangeFreq: "weekly",
-		Priority:   "1.0",
-	})
+			dstPath := filepath.Join(outDir, filepath.FromSlash(path))
+			if entry.IsDir() {
+				return os.MkdirAll(dstPath, 0755)
+			}
 
-	// Add individual notes
-	for _, note := range notes {
-		sitemap.URLs = append(sitemap.URLs, SitemapURL{
-			Loc:        fmt.Sprintf("%s/%s/", baseURL, note.Slug),
-			LastMod:    lastMod,
-			ChangeFreq: "monthly",
-			Priority:   "0.8",
+			if previous, ok := copiedFrom[path]; ok {
+				warnings.Warnf("%s from %s overrides the file from %s", path, root.Name, previous)
+				// Drop the overridden asset, whose fingerprinted name differs
+				if hashed, ok := assets[path]; ok {
+					if err := os.Remove(filepath.Join(outDir, filepath.FromSlash(hashed))); err != nil {
+						return err
+					}
+				}
+			}
+			copiedFrom[path] = root.Name
+
+			if isFingerprinted(path) {
+				data, err := fs.ReadFile(root.FS, path)
+				if err != nil {
+					return err
+				}
+				hashed := fingerprint(path, data)
+				assets[path] = hashed
+				return os.WriteFile(filepath.Join(outDir, filepath.FromSlash(hashed)), data, 0644)
+			}
+			return copyFile(root.FS, path, dstPath)
 		})
+		if err != nil {
+			return assets, fmt.Errorf("%s: %w", root.Name, err)
+		}
 	}
+	return assets, nil
+}
 
-	// Write XML with proper encoding
-	encoder := xml.NewEncoder(f)
-	encoder.Indent("", "  ")
+func copyFile(fsys fs.FS, srcPath, dstPath string) error {
+	src, err := fsys.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
 
-	// Write XML header
-	if _, err := f.WriteString(xml.Header); err != nil {
+	dst, err := os.Create(dstPath)
+	if err != nil {
 		return err
 	}
+	defer dst.Close()
+
+	_, err = io.Copy(dst, src)
+	return err
+}
+
+// absoluteURL returns ref as an absolute URL, resolving site paths such as
+// /images/cover.png against baseURL
+func absoluteURL(baseURL, ref string) string {
+	if strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://") {
+		return ref
+	}
+	return baseURL + "/" + strings.TrimPrefix(ref, "/")
+}
+
+// lastModified returns the date a note was last changed, preferring the
+// updated date over the publish date, or an empty string when neither is set
+func lastModified(note Note) string {
+	if note.Updated != "" {
+		return note.Updated
+	}
+	return note.Date
+}
 
-	// Encode sitemap
-	return encoder.Encode(sitemap)
+// siteLastUpdated returns the newest date or updated date of the published
+// notes, or buildTime when none of them are dated
+func siteLastUpdated(notes []Note, buildTime time.Time) time.Time {
+	var latest time.Time
+	for _, note := range publishedNotes(notes) {
+		date, err := time.Parse(dateLayout, lastModified(note))
+		if err == nil && date.After(latest) {
+			latest = date
+		}
+	}
+	if latest.IsZero() {
+		return buildTime
+	}
+	return latest
+}
+
+// publishedNotes returns notes excluding drafts, for use in outputs such as
+// the sitemap and feeds that should never list unpublished content
+func publishedNotes(notes []Note) []Note {
+	published := make([]Note, 0, len(notes))
+	for _, note := range notes {
+		if !note.Draft {
+			published = append(published, note)
+		}
+	}
+	return published
+}
+
+// indexableNotes returns published notes that search engines and feed
+// readers may list, excluding notes marked noindex
+func indexableNotes(notes []Note) []Note {
+	indexable := make([]Note, 0, len(notes))
+	for _, note := range publishedNotes(notes) {
+		if !note.NoIndex {
+			indexable = append(indexable, note)
+		}
+	}
+	return indexable
 }
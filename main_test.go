@@ -1,69 +1,77 @@
 package main
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
-
-	"gopkg.in/yaml.v3"
+	"testing/fstest"
+	"time"
 )
 
-// TestContentFilesStructure validates that all YAML files in the content directory
-// adhere to the required structure and constraints
+// TestContentFilesStructure validates that all content files in the content directory
+// adhere to the required structure and constraints, including drafts that are
+// excluded from the build
 func TestContentFilesStructure(t *testing.T) {
-	// Read all files in the content directory
-	entries, err := os.ReadDir("content")
+	// Walk the content directory, including nested subdirectories
+	var contentFiles []string
+	err := filepath.WalkDir("content", func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		// Filter to supported content files
+		if _, ok := noteDecoders[filepath.Ext(path)]; !entry.IsDir() && ok {
+			contentFiles = append(contentFiles, path)
+		}
+		return nil
+	})
 	if err != nil {
 		t.Fatalf("Failed to read content directory: %v", err)
 	}
 
-	// Filter to only YAML files
-	var yamlFiles []string
-	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".yaml") {
-			yamlFiles = append(yamlFiles, entry.Name())
-		}
+	if len(contentFiles) == 0 {
+		t.Fatal("No content files found in content directory")
 	}
 
-	if len(yamlFiles) == 0 {
-		t.Fatal("No YAML files found in content directory")
+	// Validate against the site's config, such as its date_layout
+	cfg, err := readConfig(defaultConfigPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
 	}
 
-	// Run parametric test for each YAML file
-	for _, filename := range yamlFiles {
-		t.Run(filename, func(t *testing.T) {
-			validateContentFile(t, filepath.Join("content", filename))
+	// Run parametric test for each content file
+	for _, path := range contentFiles {
+		name, _ := filepath.Rel("content", path)
+		t.Run(name, func(t *testing.T) {
+			validateContentFile(t, cfg, path)
 		})
 	}
 }
 
-func validateContentFile(t *testing.T, path string) {
+func validateContentFile(t *testing.T, cfg Config, path string) {
 	// Read the file
 	data, err := os.ReadFile(path)
 	if err != nil {
 		t.Fatalf("Failed to read file: %v", err)
 	}
 
-	// Parse YAML
+	// Parse with the decoder for the file's format
 	var note Note
-	if err := yaml.Unmarshal(data, &note); err != nil {
-		t.Fatalf("Failed to parse YAML: %v", err)
-	}
-
-	// Validate required fields
-	if note.Slug == "" {
-		t.Error("slug field is required but missing or empty")
+	if err := noteDecoders[filepath.Ext(path)](data, &note); errors.Is(err, errNoFrontMatter) {
+		t.Skip("Markdown file without front matter is not a note")
+	} else if err != nil {
+		t.Fatalf("Failed to parse content file: %v", err)
 	}
 
-	if note.Title == "" {
-		t.Error("title field is required but missing or empty")
-	}
-
-	if note.Thesis == "" {
-		t.Error("thesis field is required but missing or empty")
+	// Validate the invariants also enforced by the build
+	if err := validateNote(note, path, cfg.MaxLengths); err != nil {
+		t.Error(err)
 	}
 
+	// Validate fields required of published content
 	if len(note.Bullets) == 0 {
 		t.Error("bullets field is required but missing or empty")
 	}
@@ -72,20 +80,14 @@ func validateContentFile(t *testing.T, path string) {
 		t.Error("tags field is required but missing or empty")
 	}
 
-	// Validate slug format (should be lowercase with hyphens, no spaces or special chars)
-	if note.Slug != "" {
-		if strings.Contains(note.Slug, " ") {
-			t.Error("slug should not contain spaces")
-		}
-		if strings.ToLower(note.Slug) != note.Slug {
-			t.Error("slug should be lowercase")
+	// Validate aliases use the same format as slugs
+	for _, alias := range note.Aliases {
+		if alias == "" || alias == note.Slug {
+			t.Errorf("alias %q should be non-empty and differ from the slug", alias)
+			continue
 		}
-		// Check for only alphanumeric and hyphens
-		for _, ch := range note.Slug {
-			if !((ch >= 'a' && ch <= 'z') || (ch >= '0' && ch <= '9') || ch == '-') {
-				t.Errorf("slug contains invalid character '%c', should only contain lowercase letters, numbers, and hyphens", ch)
-				break
-			}
+		if !slugPattern.MatchString(alias) {
+			t.Errorf("alias %q should only contain lowercase letters, numbers, and hyphens", alias)
 		}
 	}
 
@@ -111,22 +113,540 @@ func validateContentFile(t *testing.T, path string) {
 		if link.URL == "" {
 			t.Errorf("link at index %d is missing url", i)
 		}
-		// Basic URL validation - should start with http:// or https://
-		if link.URL != "" && !strings.HasPrefix(link.URL, "http://") && !strings.HasPrefix(link.URL, "https://") {
+		// Basic URL validation - should start with http:// or https:// unless it references another note
+		if _, internal := internalSlug(link.URL, ""); link.URL != "" && !internal && !strings.HasPrefix(link.URL, "http://") && !strings.HasPrefix(link.URL, "https://") {
 			t.Errorf("link at index %d has invalid URL '%s', should start with http:// or https://", i, link.URL)
 		}
 	}
 
-	// Validate theme (if present) is non-empty
+	// Validate theme (if present) is non-empty and styled by the static CSS
 	// Note: theme is optional (default is "default" as per main.go)
 	if note.Theme != "" && strings.TrimSpace(note.Theme) == "" {
 		t.Error("theme field should not be whitespace only if present")
 	}
+	if note.Theme != "" {
+		themes, err := availableThemes([]staticRoot{{Name: "static", FS: os.DirFS("static")}})
+		if err != nil {
+			t.Fatalf("Failed to read themes: %v", err)
+		}
+		if err := checkTheme(note.Theme, themes); err != nil {
+			t.Error(err)
+		}
+	}
+
+	// Validate dates (if present) use the configured layout, as the build does
+	if note.Date != "" {
+		if _, err := parseNoteDate(note.Date, cfg.DateLayout); err != nil {
+			t.Errorf("date '%s' is invalid, should use the format %s", note.Date, cfg.DateLayout)
+		}
+	}
+	if note.Updated != "" {
+		if _, err := parseNoteDate(note.Updated, cfg.DateLayout); err != nil {
+			t.Errorf("updated '%s' is invalid, should use the format %s", note.Updated, cfg.DateLayout)
+		}
+	}
+
+	// Validate image (if present) is a site path or URL
+	if note.Image != "" {
+		if strings.TrimSpace(note.Image) == "" {
+			t.Error("image field should not be whitespace only if present")
+		} else if !strings.HasPrefix(note.Image, "/") && !strings.HasPrefix(note.Image, "http://") && !strings.HasPrefix(note.Image, "https://") {
+			t.Errorf("image '%s' should be a path starting with / or a URL starting with http:// or https://", note.Image)
+		}
+	}
+
+	// Validate sitemap changefreq and priority (if present)
+	if err := validateSitemapHints(note.ChangeFreq, note.Priority); err != nil {
+		t.Error(err)
+	}
+
+	// Validate diagram type (if present) is supported
+	if note.DiagramType != "" {
+		if _, err := resolveDiagramType(note); err != nil {
+			t.Error(err)
+		}
+	}
+
+	// Validate that footnote references and footnotes match up
+	rendered := note
+	if err := renderNoteMarkdown(&rendered); err != nil {
+		t.Errorf("rendering markdown: %v", err)
+	}
+
+	// Validate that notes in a series are numbered
+	if note.Series != "" && note.SeriesOrder < 1 {
+		t.Error("series_order must be a positive number when series is set")
+	}
+
+	// Validate author (if present) is non-empty
+	if note.Author != "" && strings.TrimSpace(note.Author) == "" {
+		t.Error("author field should not be whitespace only if present")
+	}
+}
+
+func TestValidateContentFileDateLayout(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "content")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "dated.yaml")
+	data := "slug: dated\ntitle: Dated\nthesis: Dated\nbullets: [One]\ntags: [test]\ndate: Mar 14, 2026\nupdated: 2026-03-20\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := testConfig(t)
+	cfg.DateLayout = "Jan 2, 2006"
+	validateContentFile(t, cfg, path)
+}
+
+func TestCheckDuplicateSlugs(t *testing.T) {
+	notes := []Note{
+		{Slug: "same", Source: "content/a.yaml"},
+		{Slug: "other", Source: "content/b.yaml"},
+		{Slug: "same", Source: "content/nested/same.yaml"},
+	}
+
+	err := checkDuplicateSlugs(notes)
+	if err == nil {
+		t.Fatal("expected an error for duplicate slugs, got nil")
+	}
+	for _, want := range []string{"same", "content/a.yaml", "content/nested/same.yaml"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q should mention %q", err, want)
+		}
+	}
+
+	if err := checkDuplicateSlugs(notes[:2]); err != nil {
+		t.Errorf("expected no error for unique slugs, got %v", err)
+	}
+
+	nested := []Note{{Slug: "go", Source: "content/go.yaml"}, {Slug: "go/concurrency", Source: "content/go/concurrency.yaml"}}
+	if err := checkDuplicateSlugs(nested); err != nil {
+		t.Errorf("expected a note and a note nested below it to coexist, got %v", err)
+	}
+	for _, slug := range []string{"tags/go", "archive", "go/index"} {
+		if err := checkDuplicateSlugs([]Note{{Slug: slug, Source: "content/x.yaml"}}); err == nil {
+			t.Errorf("expected an error for slug %q overwriting a generated page", slug)
+		}
+	}
+}
+
+func TestGenerateNotePageNestedSlug(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.BasePath = ""
+	tmpl, err := parseTemplate(siteFS, templateFuncs(cfg, nil), "templates/note.html", "templates/footer.html")
+	if err != nil {
+		t.Fatalf("Failed to parse note template: %v", err)
+	}
+
+	outDir := cfg.OutputDir
+	note := Note{Slug: "go/concurrency", Title: "Concurrency"}
+	data := NotePageData{
+		Note:        note,
+		Breadcrumbs: noteBreadcrumbs(cfg, note, map[string]string{"go": "Go", "go/concurrency": "Concurrency"}),
+	}
+	if err := generateNotePage(cfg, tmpl, data); err != nil {
+		t.Fatalf("generateNotePage returned error: %v", err)
+	}
+
+	for _, path := range []string{"go/concurrency.html", "go/concurrency/index.html"} {
+		content, err := os.ReadFile(filepath.Join(outDir, filepath.FromSlash(path)))
+		if err != nil {
+			t.Errorf("expected %s to be written: %v", path, err)
+			continue
+		}
+		if !strings.Contains(string(content), `<li><a href="/go/">Go</a></li>`) {
+			t.Errorf("%s should link to its parent in the breadcrumbs", path)
+		}
+	}
+}
+
+func TestSortNotes(t *testing.T) {
+	notes := []Note{
+		{Slug: "zeta"},
+		{Slug: "alpha"},
+		{Slug: "second", Order: 2},
+		{Slug: "first", Order: 1},
+		{Slug: "also-second", Order: 2},
+	}
+
+	sortNotes(notes)
+
+	want := []string{"first", "also-second", "second", "alpha", "zeta"}
+	for i, slug := range want {
+		if notes[i].Slug != slug {
+			t.Errorf("position %d: expected %q, got %q", i, slug, notes[i].Slug)
+		}
+	}
+}
+
+func TestNoteWordCount(t *testing.T) {
+	note := Note{
+		Thesis:  "A POC cuts corners but not scope.",
+		Bullets: []string{"One two three.", "  Four   five  "},
+		Example: "six seven",
+	}
+
+	if got := noteWordCount(note); got != 14 {
+		t.Errorf("expected 14 words, got %d", got)
+	}
+}
+
+func TestReadingTime(t *testing.T) {
+	tests := []struct {
+		words int
+		want  int
+	}{
+		{0, 1},
+		{1, 1},
+		{200, 1},
+		{201, 2},
+		{650, 4},
+	}
+
+	for _, tt := range tests {
+		if got := readingTime(tt.words); got != tt.want {
+			t.Errorf("readingTime(%d) = %d, want %d", tt.words, got, tt.want)
+		}
+	}
+}
+
+func TestCopyStaticFilesNested(t *testing.T) {
+	fsys := fstest.MapFS{
+		"style.css":       {Data: []byte("body {}")},
+		"images/logo.png": {Data: []byte("png")},
+	}
+	outDir := t.TempDir()
+
+	// Pre-existing output files are overwritten
+	if err := os.MkdirAll(filepath.Join(outDir, "images"), 0755); err != nil {
+		t.Fatalf("Failed to create images directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(outDir, "images", "logo.png"), []byte("stale"), 0644); err != nil {
+		t.Fatalf("Failed to write stale file: %v", err)
+	}
+
+	assets, err := copyStaticFiles(outDir, []staticRoot{{Name: "static", FS: fsys}}, newReporter(false))
+	if err != nil {
+		t.Fatalf("copyStaticFiles returned error: %v", err)
+	}
+
+	hashedCSS, ok := assets["style.css"]
+	if !ok {
+		t.Fatal("expected style.css to be fingerprinted")
+	}
+	if _, ok := assets["images/logo.png"]; ok {
+		t.Error("images should not be fingerprinted")
+	}
+
+	for path, want := range map[string]string{
+		hashedCSS:         "body {}",
+		"images/logo.png": "png",
+	} {
+		got, err := os.ReadFile(filepath.Join(outDir, filepath.FromSlash(path)))
+		if err != nil {
+			t.Errorf("expected %s to be copied: %v", path, err)
+			continue
+		}
+		if string(got) != want {
+			t.Errorf("%s has content %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestCopyStaticFilesLaterRootOverrides(t *testing.T) {
+	shared := fstest.MapFS{
+		"style.css":       {Data: []byte("body { color: black; }")},
+		"images/logo.png": {Data: []byte("shared logo")},
+		"favicon.ico":     {Data: []byte("shared icon")},
+	}
+	project := fstest.MapFS{
+		"style.css":       {Data: []byte("body { color: blue; }")},
+		"images/logo.png": {Data: []byte("project logo")},
+	}
+	outDir := t.TempDir()
+
+	var warnings bytes.Buffer
+	assets, err := copyStaticFiles(outDir, []staticRoot{{Name: "shared", FS: shared}, {Name: "project", FS: project}}, &reporter{out: &warnings})
+	if err != nil {
+		t.Fatalf("copyStaticFiles returned error: %v", err)
+	}
+
+	want := fingerprint("style.css", []byte("body { color: blue; }"))
+	if assets["style.css"] != want {
+		t.Errorf("style.css fingerprinted as %q, want %q from the later root", assets["style.css"], want)
+	}
+	overridden := fingerprint("style.css", []byte("body { color: black; }"))
+	if _, err := os.Stat(filepath.Join(outDir, overridden)); !os.IsNotExist(err) {
+		t.Errorf("expected overridden asset %s to be removed, got %v", overridden, err)
+	}
+	if !strings.Contains(warnings.String(), "images/logo.png from project overrides the file from shared") {
+		t.Errorf("expected a warning naming the overridden file, got %q", warnings.String())
+	}
+
+	for path, want := range map[string]string{
+		"images/logo.png": "project logo",
+		"favicon.ico":     "shared icon",
+	} {
+		got, err := os.ReadFile(filepath.Join(outDir, filepath.FromSlash(path)))
+		if err != nil {
+			t.Errorf("expected %s to be copied: %v", path, err)
+			continue
+		}
+		if string(got) != want {
+			t.Errorf("%s has content %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestNotePageCanonicalURL(t *testing.T) {
+	tmpl, err := parseTemplate(siteFS, templateFuncs(Config{}, nil), "templates/note.html", "templates/footer.html")
+	if err != nil {
+		t.Fatalf("Failed to parse note template: %v", err)
+	}
+
+	data := NotePageData{
+		Note:         Note{Slug: "example", Title: "Example", Thesis: "On-page text."},
+		Lang:         "de",
+		Description:  "Search snippet text.",
+		CanonicalURL: "https://notes.example.com/example/",
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		t.Fatalf("Failed to execute note template: %v", err)
+	}
+
+	for _, want := range []string{
+		`<html lang="de">`,
+		`<link rel="canonical" href="https://notes.example.com/example/">`,
+		`<meta name="description" content="Search snippet text.">`,
+		`<meta property="og:description" content="Search snippet text.">`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("rendered note page does not contain %s", want)
+		}
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		max  int
+		want string
+	}{
+		{"short text unchanged", "Short thesis.", 160, "Short thesis."},
+		{"exact length unchanged", "abcde", 5, "abcde"},
+		{"cuts on word boundary", "The quick brown fox jumps", 16, "The quick brown…"},
+		{"does not cut words", "The quick brown fox jumps", 13, "The quick…"},
+		{"trims trailing punctuation", "First part, second part", 13, "First part…"},
+		{"single long word", "Supercalifragilistic", 8, "Superca…"},
+		{"counts runes", "héllo wörld again", 12, "héllo wörld…"},
+	}
 
-	// Validate that filename matches slug
-	expectedFilename := note.Slug + ".yaml"
-	actualFilename := filepath.Base(path)
-	if expectedFilename != actualFilename {
-		t.Errorf("filename '%s' does not match slug '%s' (expected '%s')", actualFilename, note.Slug, expectedFilename)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := summarize(tt.s, tt.max)
+			if got != tt.want {
+				t.Errorf("summarize(%q, %d) = %q, want %q", tt.s, tt.max, got, tt.want)
+			}
+			if n := len([]rune(got)); n > tt.max {
+				t.Errorf("summarize(%q, %d) returned %d characters", tt.s, tt.max, n)
+			}
+		})
+	}
+}
+
+func TestAbsoluteURL(t *testing.T) {
+	tests := map[string]string{
+		"/images/cover.png":                 "https://notes.example.com/images/cover.png",
+		"images/cover.png":                  "https://notes.example.com/images/cover.png",
+		"https://cdn.example.com/cover.png": "https://cdn.example.com/cover.png",
+	}
+	for ref, want := range tests {
+		if got := absoluteURL("https://notes.example.com", ref); got != want {
+			t.Errorf("absoluteURL(%q) = %q, want %q", ref, got, want)
+		}
+	}
+}
+
+func TestTemplatesExposeTheme(t *testing.T) {
+	funcs := templateFuncs(Config{}, nil)
+	note := Note{Slug: "example", Title: "Example", Theme: "blue"}
+
+	noteTmpl, err := parseTemplate(siteFS, funcs, "templates/note.html", "templates/footer.html")
+	if err != nil {
+		t.Fatalf("Failed to parse note template: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := noteTmpl.Execute(&buf, NotePageData{Note: note}); err != nil {
+		t.Fatalf("Failed to execute note template: %v", err)
+	}
+	for _, want := range []string{`<body class="page-note" data-theme="blue">`, `class="note-detail blue"`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("rendered note page does not contain %s", want)
+		}
+	}
+
+	indexTmpl, err := parseTemplate(siteFS, funcs, "templates/index.html", "templates/footer.html")
+	if err != nil {
+		t.Fatalf("Failed to parse index template: %v", err)
+	}
+	buf.Reset()
+	if err := indexTmpl.Execute(&buf, IndexData{Notes: []Note{note}}); err != nil {
+		t.Fatalf("Failed to execute index template: %v", err)
+	}
+	if want := `class="note-card blue" data-theme="blue"`; !strings.Contains(buf.String(), want) {
+		t.Errorf("rendered index page does not contain %s", want)
+	}
+}
+
+func TestFooterContainsCurrentYear(t *testing.T) {
+	tmpl, err := parseTemplate(siteFS, templateFuncs(Config{}, nil), "templates/index.html", "templates/footer.html")
+	if err != nil {
+		t.Fatalf("Failed to parse index template: %v", err)
+	}
+
+	cfg := testConfig(t)
+	data := IndexData{Site: newSiteData(cfg), Footer: newFooterData(cfg)}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		t.Fatalf("Failed to execute index template: %v", err)
+	}
+
+	footer := buf.String()[strings.Index(buf.String(), `<footer class="site-footer">`):]
+	want := fmt.Sprintf("%d %s", time.Now().Year(), cfg.SiteTitle)
+	if !strings.Contains(footer, want) {
+		t.Errorf("rendered footer does not contain %q:\n%s", want, footer)
+	}
+}
+
+func TestIndexPageSiteMetadata(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.PageSize = 1
+	cfg.BuildTime = time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC)
+	tmpl, err := parseTemplate(siteFS, templateFuncs(cfg, nil), "templates/index.html", "templates/footer.html")
+	if err != nil {
+		t.Fatalf("Failed to parse index template: %v", err)
+	}
+	if err := generateIndex(cfg, tmpl, []Note{{Slug: "a", Title: "A", Date: "2025-06-12"}}); err != nil {
+		t.Fatalf("generateIndex returned error: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(cfg.OutputDir, "index.html"))
+	if err != nil {
+		t.Fatalf("Failed to read index page: %v", err)
+	}
+	for _, want := range []string{
+		`<link rel="canonical" href="https://notes.example.com/">`,
+		`<meta property="og:title" content="` + cfg.SiteTitle + `">`,
+		`<meta property="og:description" content="` + cfg.Description + `">`,
+		`<meta property="og:url" content="https://notes.example.com/">`,
+		`<meta name="twitter:title" content="` + cfg.SiteTitle + `">`,
+		`1 note · `,
+		`· updated 3 days ago</div>`,
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("index page does not contain %s", want)
+		}
+	}
+}
+
+func TestSiteLastUpdated(t *testing.T) {
+	buildTime := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	notes := []Note{
+		{Slug: "a", Date: "2024-01-15"},
+		{Slug: "b", Date: "2024-02-01", Updated: "2024-03-10"},
+		{Slug: "c", Date: "2024-02-20"},
+		{Slug: "draft", Date: "2024-12-31", Draft: true},
+	}
+
+	want := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+	if got := siteLastUpdated(notes, buildTime); !got.Equal(want) {
+		t.Errorf("siteLastUpdated() = %v, want %v", got, want)
+	}
+	if got := siteLastUpdated([]Note{{Slug: "undated"}}, buildTime); !got.Equal(buildTime) {
+		t.Errorf("siteLastUpdated() without dates = %v, want the build time %v", got, buildTime)
+	}
+
+	cfg := testConfig(t)
+	cfg.LastUpdated = want
+	if footer := newFooterData(cfg); footer.LastUpdated != "2024-03-10" {
+		t.Errorf("footer last updated = %q, want 2024-03-10", footer.LastUpdated)
+	}
+}
+
+func TestReproducibleBuild(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+
+	build := func() map[string][]byte {
+		cfg := testConfig(t)
+		if err := run(cfg, siteFS); err != nil {
+			t.Fatalf("run failed: %v", err)
+		}
+		files := make(map[string][]byte)
+		err := filepath.WalkDir(cfg.OutputDir, func(path string, d os.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			rel, _ := filepath.Rel(cfg.OutputDir, path)
+			files[rel] = data
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("walking output: %v", err)
+		}
+		return files
+	}
+
+	first, second := build(), build()
+	if len(first) != len(second) {
+		t.Fatalf("builds produced %d and %d files", len(first), len(second))
+	}
+	for name, data := range first {
+		if !bytes.Equal(data, second[name]) {
+			t.Errorf("%s differs between builds", name)
+		}
+	}
+
+	sitemap := string(first["sitemap.xml"])
+	if !strings.Contains(sitemap, "<lastmod>2023-11-14</lastmod>") {
+		t.Error("expected the homepage lastmod to come from SOURCE_DATE_EPOCH")
+	}
+}
+
+func TestPagesIncludeConfiguredCSP(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.CSP = "default-src 'self'; img-src 'self' https:"
+	if err := run(cfg, siteFS); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	want := `<meta http-equiv="Content-Security-Policy" content="default-src &#39;self&#39;; img-src &#39;self&#39; https:">`
+	for _, page := range []string{"index.html", "archive/index.html", "404.html", "data-driven-testing/index.html"} {
+		content, err := os.ReadFile(filepath.Join(cfg.OutputDir, filepath.FromSlash(page)))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", page, err)
+		}
+		if !strings.Contains(string(content), want) {
+			t.Errorf("%s does not contain the CSP meta tag", page)
+		}
+	}
+
+	cfg = testConfig(t)
+	if err := run(cfg, siteFS); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(cfg.OutputDir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "Content-Security-Policy") {
+		t.Error("the CSP meta tag should only be written when csp is configured")
 	}
 }
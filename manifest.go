@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// BuildManifest is a machine-readable summary of a build
+type BuildManifest struct {
+	BuildTime string          `json:"build_time"`
+	NoteCount int             `json:"note_count"`
+	TagCount  int             `json:"tag_count"`
+	Phases    *PhaseDurations `json:"phases_ms,omitempty"`
+	Content   ContentStats    `json:"content"`
+}
+
+// PhaseDurations records how long each build phase took in milliseconds
+type PhaseDurations struct {
+	Read    float64 `json:"read"`
+	Render  float64 `json:"render"`
+	Static  float64 `json:"static"`
+	Sitemap float64 `json:"sitemap"`
+	Total   float64 `json:"total"`
+}
+
+// buildTime returns the instant a build is stamped with. When the
+// SOURCE_DATE_EPOCH environment variable is set, the build is reproducible
+// and stamped with that time; otherwise it is stamped with the current time.
+func buildTime() (t time.Time, reproducible bool, err error) {
+	epoch := os.Getenv("SOURCE_DATE_EPOCH")
+	if epoch == "" {
+		return time.Now(), false, nil
+	}
+	seconds, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid SOURCE_DATE_EPOCH %q: %w", epoch, err)
+	}
+	return time.Unix(seconds, 0).UTC(), true, nil
+}
+
+// durationMillis converts d to fractional milliseconds
+func durationMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// ContentStats summarizes the length of the notes in a build, counting the
+// thesis, bullets, and example of each note
+type ContentStats struct {
+	TotalWords      int       `json:"total_words"`
+	TotalCharacters int       `json:"total_characters"`
+	AverageWords    float64   `json:"average_words"`
+	Longest         NoteWords `json:"longest"`
+	Shortest        NoteWords `json:"shortest"`
+}
+
+// NoteWords is the word count of a single note
+type NoteWords struct {
+	Slug  string `json:"slug"`
+	Words int    `json:"words"`
+}
+
+// contentStats computes word and character statistics across notes. When
+// notes tie for longest or shortest, the first in order is reported.
+func contentStats(notes []Note) ContentStats {
+	var stats ContentStats
+	for i, note := range notes {
+		words := noteWordCount(note)
+		stats.TotalWords += words
+		stats.TotalCharacters += noteCharCount(note)
+		if i == 0 || words > stats.Longest.Words {
+			stats.Longest = NoteWords{Slug: note.Slug, Words: words}
+		}
+		if i == 0 || words < stats.Shortest.Words {
+			stats.Shortest = NoteWords{Slug: note.Slug, Words: words}
+		}
+	}
+	if len(notes) > 0 {
+		stats.AverageWords = float64(stats.TotalWords) / float64(len(notes))
+	}
+	return stats
+}
+
+func writeManifest(outDir string, manifest BuildManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(outDir, "build-manifest.json"), data, 0644)
+}
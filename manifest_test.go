@@ -0,0 +1,59 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestContentStats(t *testing.T) {
+	notes := []Note{
+		{Slug: "short", Thesis: "Two words"},
+		{Slug: "long", Thesis: "One two three", Bullets: []string{"four five", "six"}, Example: "seven"},
+		{Slug: "medium", Thesis: "One two", Example: "three"},
+		{Slug: "also-short", Thesis: "Two words"},
+	}
+
+	stats := contentStats(notes)
+	if stats.TotalWords != 14 {
+		t.Errorf("TotalWords = %d, want 14", stats.TotalWords)
+	}
+	if stats.AverageWords != 3.5 {
+		t.Errorf("AverageWords = %v, want 3.5", stats.AverageWords)
+	}
+	if stats.Longest != (NoteWords{Slug: "long", Words: 7}) {
+		t.Errorf("Longest = %+v, want long with 7 words", stats.Longest)
+	}
+	if stats.Shortest != (NoteWords{Slug: "short", Words: 2}) {
+		t.Errorf("Shortest = %+v, want short with 2 words", stats.Shortest)
+	}
+	if want := 9 + 13 + 9 + 3 + 5 + 7 + 5 + 9; stats.TotalCharacters != want {
+		t.Errorf("TotalCharacters = %d, want %d", stats.TotalCharacters, want)
+	}
+}
+
+func TestContentStatsEmpty(t *testing.T) {
+	if stats := contentStats(nil); stats != (ContentStats{}) {
+		t.Errorf("contentStats(nil) = %+v, want zero value", stats)
+	}
+}
+
+func TestBuildTime(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	stamp, reproducible, err := buildTime()
+	if err != nil {
+		t.Fatalf("buildTime returned error: %v", err)
+	}
+	if !reproducible || stamp.Format(time.RFC3339) != "2023-11-14T22:13:20Z" {
+		t.Errorf("buildTime() = %v, %v; want 2023-11-14T22:13:20Z, true", stamp, reproducible)
+	}
+
+	t.Setenv("SOURCE_DATE_EPOCH", "yesterday")
+	if _, _, err := buildTime(); err == nil {
+		t.Error("expected an error for a malformed SOURCE_DATE_EPOCH")
+	}
+
+	t.Setenv("SOURCE_DATE_EPOCH", "")
+	if _, reproducible, _ := buildTime(); reproducible {
+		t.Error("builds should not be reproducible without SOURCE_DATE_EPOCH")
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"html/template"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+)
+
+// markdown converts Markdown to HTML; raw HTML in the source is omitted
+var markdown = goldmark.New()
+
+// inlinePolicy permits only inline formatting so thesis and bullets cannot
+// introduce block elements that break the page layout
+var inlinePolicy = newInlinePolicy()
+
+// blockPolicy permits common block elements for longer fields like example
+var blockPolicy = bluemonday.UGCPolicy()
+
+func newInlinePolicy() *bluemonday.Policy {
+	p := bluemonday.NewPolicy()
+	p.AllowElements("em", "strong", "code", "del", "br")
+	p.AllowAttrs("href").OnElements("a")
+	p.AllowURLSchemes("http", "https", "mailto")
+	p.AllowRelativeURLs(true)
+	p.RequireParseableURLs(true)
+	return p
+}
+
+// renderInlineMarkdown converts s to sanitized HTML containing only inline elements
+func renderInlineMarkdown(s string) (template.HTML, error) {
+	return renderMarkdown(s, inlinePolicy)
+}
+
+// renderBlockMarkdown converts s to sanitized HTML that may contain block elements
+func renderBlockMarkdown(s string) (template.HTML, error) {
+	return renderMarkdown(s, blockPolicy)
+}
+
+func renderMarkdown(s string, policy *bluemonday.Policy) (template.HTML, error) {
+	var buf bytes.Buffer
+	if err := markdown.Convert([]byte(s), &buf); err != nil {
+		return "", err
+	}
+	html := strings.TrimSpace(policy.Sanitize(buf.String()))
+	return template.HTML(html), nil
+}
+
+// renderNoteMarkdown populates the rendered HTML fields of note
+func renderNoteMarkdown(note *Note) error {
+	renderInline, renderBlock := renderInlineMarkdown, renderBlockMarkdown
+	if note.Math {
+		renderInline = func(s string) (template.HTML, error) { return renderMathMarkdown(s, inlinePolicy) }
+		renderBlock = func(s string) (template.HTML, error) { return renderMathMarkdown(s, blockPolicy) }
+	}
+
+	var err error
+	if note.ThesisHTML, err = renderInline(note.Thesis); err != nil {
+		return err
+	}
+
+	note.BulletsHTML = make([]template.HTML, 0, len(note.Bullets))
+	for _, bullet := range note.Bullets {
+		html, err := renderInline(bullet)
+		if err != nil {
+			return err
+		}
+		note.BulletsHTML = append(note.BulletsHTML, html)
+	}
+
+	switch {
+	case note.Example != "" && note.ExampleLang != "":
+		if note.ExampleHTML, err = highlightCode(note.Example, note.ExampleLang); err != nil {
+			return err
+		}
+	case note.Example != "":
+		if note.ExampleHTML, err = renderBlock(note.Example); err != nil {
+			return err
+		}
+	}
+
+	if note.Body != "" {
+		if note.BodyHTML, err = renderBlock(note.Body); err != nil {
+			return err
+		}
+	}
+
+	return linkFootnotes(note)
+}
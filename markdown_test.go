@@ -0,0 +1,43 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderInlineMarkdown(t *testing.T) {
+	html, err := renderInlineMarkdown("Use **bold** and [a link](https://example.com)")
+	if err != nil {
+		t.Fatalf("renderInlineMarkdown returned error: %v", err)
+	}
+
+	got := string(html)
+	if !strings.Contains(got, "<strong>bold</strong>") {
+		t.Errorf("expected emphasis to be rendered, got %q", got)
+	}
+	if !strings.Contains(got, `<a href="https://example.com"`) {
+		t.Errorf("expected link to be rendered, got %q", got)
+	}
+	if strings.Contains(got, "<p>") {
+		t.Errorf("inline markdown should not contain block elements, got %q", got)
+	}
+}
+
+func TestRenderMarkdownStripsScripts(t *testing.T) {
+	input := "Hello <script>alert(1)</script> [x](javascript:alert(1))"
+
+	for name, render := range map[string]func(string) (string, error){
+		"inline": func(s string) (string, error) { h, err := renderInlineMarkdown(s); return string(h), err },
+		"block":  func(s string) (string, error) { h, err := renderBlockMarkdown(s); return string(h), err },
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, err := render(input)
+			if err != nil {
+				t.Fatalf("render returned error: %v", err)
+			}
+			if strings.Contains(got, "<script") || strings.Contains(got, "javascript:") {
+				t.Errorf("expected unsafe content to be removed, got %q", got)
+			}
+		})
+	}
+}
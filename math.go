@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+// mathPattern matches $$display$$ and $inline$ math spans. Inline spans may
+// not cross lines, so a lone dollar sign in prose is left alone.
+var mathPattern = regexp.MustCompile(`\$\$[\s\S]+?\$\$|\$[^$\n]+\$`)
+
+// mathPlaceholder stands in for a math span while the surrounding Markdown is
+// rendered, so Markdown escapes and emphasis don't alter the TeX
+const mathPlaceholder = "KATEXMATH%dEND"
+
+// renderMathMarkdown renders s like renderMarkdown, passing math spans
+// through unchanged for KaTeX to typeset in the browser
+func renderMathMarkdown(s string, policy *bluemonday.Policy) (template.HTML, error) {
+	var spans []string
+	s = mathPattern.ReplaceAllStringFunc(s, func(span string) string {
+		spans = append(spans, span)
+		return fmt.Sprintf(mathPlaceholder, len(spans)-1)
+	})
+
+	rendered, err := renderMarkdown(s, policy)
+	if err != nil {
+		return "", err
+	}
+
+	out := string(rendered)
+	for i, span := range spans {
+		out = strings.Replace(out, fmt.Sprintf(mathPlaceholder, i), html.EscapeString(span), 1)
+	}
+	return template.HTML(out), nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNotePageIncludesKaTeXOnlyForMath(t *testing.T) {
+	tmpl, err := parseTemplate(siteFS, templateFuncs(Config{}, nil), "templates/note.html", "templates/footer.html")
+	if err != nil {
+		t.Fatalf("Failed to parse note template: %v", err)
+	}
+
+	for _, math := range []bool{false, true} {
+		var buf bytes.Buffer
+		data := NotePageData{Note: Note{Slug: "example", Title: "Example", Math: math}}
+		if err := tmpl.Execute(&buf, data); err != nil {
+			t.Fatalf("Failed to execute note template: %v", err)
+		}
+		if got := strings.Contains(buf.String(), "katex.min.js"); got != math {
+			t.Errorf("with math %v, KaTeX included = %v", math, got)
+		}
+	}
+}
+
+func TestRenderMathMarkdown(t *testing.T) {
+	html, err := renderMathMarkdown(`Energy is $E = m*c^2$ and *sets* are $\{a_1, a_2\}$`, inlinePolicy)
+	if err != nil {
+		t.Fatalf("renderMathMarkdown returned error: %v", err)
+	}
+	want := `Energy is $E = m*c^2$ and <em>sets</em> are $\{a_1, a_2\}$`
+	if string(html) != want {
+		t.Errorf("got %q, want %q", html, want)
+	}
+
+	html, err = renderMathMarkdown("$$\na < b \\\\ c\n$$", blockPolicy)
+	if err != nil {
+		t.Fatalf("renderMathMarkdown returned error: %v", err)
+	}
+	if want := "$$\na &lt; b \\\\ c\n$$"; !strings.Contains(string(html), want) {
+		t.Errorf("display math was altered: %q", html)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import "fmt"
+
+// defaultPageSize is the number of notes listed on each index page
+const defaultPageSize = 20
+
+// Pagination describes where an index page sits among all index pages
+type Pagination struct {
+	Page       int
+	TotalPages int
+	PrevURL    string
+	NextURL    string
+}
+
+// pageCount returns the number of index pages needed to list n notes, which
+// is always at least one so an empty site still has a homepage
+func pageCount(n, size int) int {
+	return max(1, (n+size-1)/size)
+}
+
+// pagePath returns the site-relative path of an index page: the site root for
+// the first page and /page/N/ for the rest
+func pagePath(page int) string {
+	if page <= 1 {
+		return "/"
+	}
+	return fmt.Sprintf("/page/%d/", page)
+}
+
+// paginate returns the pagination metadata for page out of total pages
+func paginate(page, total int) Pagination {
+	p := Pagination{Page: page, TotalPages: total}
+	if page > 1 {
+		p.PrevURL = pagePath(page - 1)
+	}
+	if page < total {
+		p.NextURL = pagePath(page + 1)
+	}
+	return p
+}
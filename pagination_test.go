@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPaginate(t *testing.T) {
+	tests := []struct {
+		page, total int
+		want        Pagination
+	}{
+		{1, 1, Pagination{Page: 1, TotalPages: 1}},
+		{1, 3, Pagination{Page: 1, TotalPages: 3, NextURL: "/page/2/"}},
+		{2, 3, Pagination{Page: 2, TotalPages: 3, PrevURL: "/", NextURL: "/page/3/"}},
+		{3, 3, Pagination{Page: 3, TotalPages: 3, PrevURL: "/page/2/"}},
+	}
+	for _, tt := range tests {
+		if got := paginate(tt.page, tt.total); got != tt.want {
+			t.Errorf("paginate(%d, %d) = %+v, want %+v", tt.page, tt.total, got, tt.want)
+		}
+	}
+}
+
+func TestPageCount(t *testing.T) {
+	tests := []struct{ n, size, want int }{
+		{0, 20, 1},
+		{20, 20, 1},
+		{21, 20, 2},
+		{45, 20, 3},
+	}
+	for _, tt := range tests {
+		if got := pageCount(tt.n, tt.size); got != tt.want {
+			t.Errorf("pageCount(%d, %d) = %d, want %d", tt.n, tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateIndexPaginates(t *testing.T) {
+	tmpl, err := parseTemplate(siteFS, templateFuncs(Config{}, nil), "templates/index.html", "templates/footer.html")
+	if err != nil {
+		t.Fatalf("Failed to parse index template: %v", err)
+	}
+
+	cfg := testConfig(t)
+	cfg.PageSize = 2
+	var notes []Note
+	for i := 0; i < 5; i++ {
+		notes = append(notes, Note{Slug: fmt.Sprintf("note-%d", i), Title: fmt.Sprintf("Note %d", i)})
+	}
+	if err := generateIndex(cfg, tmpl, notes); err != nil {
+		t.Fatalf("generateIndex failed: %v", err)
+	}
+
+	pages := []struct {
+		path    string
+		slugs   []string
+		missing []string
+		links   []string
+	}{
+		{"index.html", []string{"note-0", "note-1"}, []string{"note-2"}, []string{`<link rel="canonical" href="https://notes.example.com/">`, `<link rel="next" href="/page/2/">`}},
+		{"page/2/index.html", []string{"note-2", "note-3"}, []string{"note-1", "note-4"}, []string{`<link rel="canonical" href="https://notes.example.com/page/2/">`, `<link rel="prev" href="/">`, `<link rel="next" href="/page/3/">`}},
+		{"page/3/index.html", []string{"note-4"}, []string{"note-3"}, []string{`<link rel="prev" href="/page/2/">`, "Page 3 of 3"}},
+	}
+	for _, p := range pages {
+		content, err := os.ReadFile(filepath.Join(cfg.OutputDir, p.path))
+		if err != nil {
+			t.Fatalf("expected %s to be written: %v", p.path, err)
+		}
+		html := string(content)
+		for _, slug := range p.slugs {
+			if !strings.Contains(html, `href="/`+slug+`/"`) {
+				t.Errorf("%s should list %s", p.path, slug)
+			}
+		}
+		for _, slug := range p.missing {
+			if strings.Contains(html, `href="/`+slug+`/"`) {
+				t.Errorf("%s should not list %s", p.path, slug)
+			}
+		}
+		for _, link := range p.links {
+			if !strings.Contains(html, link) {
+				t.Errorf("%s missing %q", p.path, link)
+			}
+		}
+	}
+	if _, err := os.Stat(filepath.Join(cfg.OutputDir, "page", "4")); !os.IsNotExist(err) {
+		t.Error("page 4 should not be generated")
+	}
+}
+
+func TestGenerateIndexWithBasePath(t *testing.T) {
+	for _, basePath := range []string{"", "/notes"} {
+		t.Run("base path "+basePath, func(t *testing.T) {
+			cfg := testConfig(t)
+			cfg.BasePath = basePath
+			cfg.PageSize = 1
+			tmpl, err := parseTemplate(siteFS, templateFuncs(cfg, map[string]string{"style.css": "style.abc.css"}), "templates/index.html", "templates/footer.html")
+			if err != nil {
+				t.Fatalf("Failed to parse index template: %v", err)
+			}
+
+			notes := []Note{{Slug: "first", Title: "First"}, {Slug: "second", Title: "Second"}}
+			if err := generateIndex(cfg, tmpl, notes); err != nil {
+				t.Fatalf("generateIndex failed: %v", err)
+			}
+
+			content, err := os.ReadFile(filepath.Join(cfg.OutputDir, "index.html"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			html := string(content)
+			for _, want := range []string{
+				`href="` + basePath + `/first/"`,
+				`href="` + basePath + `/style.abc.css"`,
+				`href="` + basePath + `/rss.xml"`,
+				`href="` + basePath + `/archive/"`,
+				`href="` + basePath + `/page/2/"`,
+			} {
+				if !strings.Contains(html, want) {
+					t.Errorf("index missing %s", want)
+				}
+			}
+		})
+	}
+}
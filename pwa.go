@@ -0,0 +1,155 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// defaultThemeColor is the browser UI color used when theme_color is unset
+const defaultThemeColor = "#2563eb"
+
+// ManifestIcon is an icon listed in the web app manifest
+type ManifestIcon struct {
+	Src   string `yaml:"src" json:"src"`
+	Sizes string `yaml:"sizes" json:"sizes"`
+	Type  string `yaml:"type" json:"type,omitempty"`
+}
+
+// WebManifest is the web app manifest that makes the site installable
+type WebManifest struct {
+	Name            string         `json:"name"`
+	ShortName       string         `json:"short_name"`
+	StartURL        string         `json:"start_url"`
+	Scope           string         `json:"scope"`
+	Display         string         `json:"display"`
+	BackgroundColor string         `json:"background_color"`
+	ThemeColor      string         `json:"theme_color"`
+	Icons           []ManifestIcon `json:"icons,omitempty"`
+}
+
+// serviceWorkerScript caches the index and static assets on install, serves
+// assets cache-first, and serves pages network-first while caching every page
+// visited so they remain readable offline. Caches from other builds are
+// deleted on activation so updates propagate.
+const serviceWorkerScript = `const CACHE = %s;
+const PRECACHE = %s;
+
+self.addEventListener('install', event => {
+    event.waitUntil(caches.open(CACHE).then(cache => cache.addAll(PRECACHE)));
+    self.skipWaiting();
+});
+
+self.addEventListener('activate', event => {
+    event.waitUntil(caches.keys().then(keys => Promise.all(
+        keys.filter(key => key !== CACHE).map(key => caches.delete(key))
+    )));
+    self.clients.claim();
+});
+
+self.addEventListener('fetch', event => {
+    const request = event.request;
+    if (request.method !== 'GET' || new URL(request.url).origin !== self.location.origin) {
+        return;
+    }
+
+    if (request.mode === 'navigate') {
+        event.respondWith(fetch(request).then(response => {
+            const copy = response.clone();
+            caches.open(CACHE).then(cache => cache.put(request, copy));
+            return response;
+        }).catch(() => caches.match(request)));
+        return;
+    }
+
+    event.respondWith(caches.match(request).then(cached => cached || fetch(request)));
+});
+`
+
+// generateWebManifest writes manifest.webmanifest describing the site as an
+// installable app
+func generateWebManifest(cfg Config) error {
+	manifest := WebManifest{
+		Name:            cfg.SiteTitle,
+		ShortName:       cfg.SiteTitle,
+		StartURL:        cfg.BasePath + "/",
+		Scope:           cfg.BasePath + "/",
+		Display:         "standalone",
+		BackgroundColor: "#fafbfc",
+		ThemeColor:      cfg.ThemeColor,
+	}
+	for _, icon := range cfg.Icons {
+		icon.Src = relURL(cfg.BasePath, icon.Src)
+		manifest.Icons = append(manifest.Icons, icon)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(cfg.OutputDir, "manifest.webmanifest"), data, 0644)
+}
+
+// generateServiceWorker writes sw.js, precaching the index page and the
+// fingerprinted assets. The cache is named after a hash of the built site, so
+// any change to the output installs a fresh cache.
+func generateServiceWorker(cfg Config, assets map[string]string) error {
+	hash, err := buildHash(cfg.OutputDir)
+	if err != nil {
+		return err
+	}
+
+	precache := []string{cfg.BasePath + "/"}
+	for _, hashed := range assets {
+		precache = append(precache, cfg.BasePath+"/"+hashed)
+	}
+	sort.Strings(precache[1:])
+
+	cacheName, err := json.Marshal("notes-" + hash)
+	if err != nil {
+		return err
+	}
+	urls, err := json.Marshal(precache)
+	if err != nil {
+		return err
+	}
+	script := fmt.Sprintf(serviceWorkerScript, cacheName, urls)
+	return os.WriteFile(filepath.Join(cfg.OutputDir, "sw.js"), []byte(script), 0644)
+}
+
+// buildHash returns a short hash of the path and contents of every file in
+// outDir, which changes whenever the built site changes. The service worker
+// and its compressed copies left by an earlier build are skipped, so the
+// hash does not depend on itself.
+func buildHash(outDir string) (string, error) {
+	h := sha256.New()
+	err := filepath.WalkDir(outDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(outDir, path)
+		if err != nil {
+			return err
+		}
+		if rel == "sw.js" || strings.HasPrefix(rel, "sw.js.") {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00", filepath.ToSlash(rel), len(data))
+		h.Write(data)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil))[:fingerprintLength], nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateWebManifest(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.BasePath = "/notes"
+	cfg.Icons = []ManifestIcon{{Src: "/icon-192.png", Sizes: "192x192", Type: "image/png"}}
+
+	if err := generateWebManifest(cfg); err != nil {
+		t.Fatalf("generateWebManifest returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(cfg.OutputDir, "manifest.webmanifest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest WebManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("manifest is not valid JSON: %v", err)
+	}
+	if manifest.Name != cfg.SiteTitle || manifest.ThemeColor != defaultThemeColor {
+		t.Errorf("unexpected name or theme color: %+v", manifest)
+	}
+	if manifest.StartURL != "/notes/" || manifest.Scope != "/notes/" {
+		t.Errorf("expected start URL and scope under the base path, got %q and %q", manifest.StartURL, manifest.Scope)
+	}
+	if len(manifest.Icons) != 1 || manifest.Icons[0].Src != "/notes/icon-192.png" {
+		t.Errorf("expected the icon under the base path, got %+v", manifest.Icons)
+	}
+}
+
+func TestGenerateServiceWorker(t *testing.T) {
+	cfg := testConfig(t)
+	assets := map[string]string{"style.css": "style.0123456789.css"}
+	index := filepath.Join(cfg.OutputDir, "index.html")
+	if err := os.WriteFile(index, []byte("<p>v1</p>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	readWorker := func() string {
+		if err := generateServiceWorker(cfg, assets); err != nil {
+			t.Fatalf("generateServiceWorker returned error: %v", err)
+		}
+		// The worker is left in place, as in an incremental build
+		data, err := os.ReadFile(filepath.Join(cfg.OutputDir, "sw.js"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	first := readWorker()
+	if !strings.Contains(first, `const PRECACHE = ["/","/style.0123456789.css"];`) {
+		t.Errorf("expected the index and assets to be precached:\n%s", first)
+	}
+	if again := readWorker(); again != first {
+		t.Error("service worker should be stable when the output is unchanged")
+	}
+
+	if err := os.WriteFile(index, []byte("<p>v2</p>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cacheLine := func(script string) string { return strings.SplitN(script, "\n", 2)[0] }
+	if changed := readWorker(); cacheLine(changed) == cacheLine(first) {
+		t.Errorf("cache name should change when the output changes, got %s", cacheLine(changed))
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// RandomData holds data for the random note template
+type RandomData struct {
+	Lang       string
+	CSP        string
+	SiteTitle  string
+	URLs       []string
+	ArchiveURL string
+}
+
+// randomNoteURLs returns the site-relative URL of every published note,
+// sorted by slug so the page is identical between builds of the same content
+func randomNoteURLs(cfg Config, notes []Note) []string {
+	published := publishedNotes(notes)
+	urls := make([]string, 0, len(published))
+	for _, note := range published {
+		urls = append(urls, cfg.BasePath+notePath(cfg, note.Slug))
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// generateRandomPage writes random/index.html, which redirects the browser
+// to a note picked at random from the embedded list of note URLs
+func generateRandomPage(cfg Config, tmpl *template.Template, notes []Note) error {
+	dir := filepath.Join(cfg.OutputDir, "random")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	f, err := os.Create(filepath.Join(dir, "index.html"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	data := RandomData{
+		Lang:       cfg.DefaultLang,
+		CSP:        cfg.CSP,
+		SiteTitle:  cfg.SiteTitle,
+		URLs:       randomNoteURLs(cfg, notes),
+		ArchiveURL: cfg.BasePath + "/archive/",
+	}
+	return tmpl.Execute(f, data)
+}
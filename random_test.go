@@ -0,0 +1,31 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateRandomPage(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.BasePath = "/notes"
+	tmpl, err := parseTemplate(siteFS, templateFuncs(cfg, nil), "templates/random.html")
+	if err != nil {
+		t.Fatalf("Failed to parse random template: %v", err)
+	}
+
+	notes := []Note{{Slug: "zeta"}, {Slug: "alpha"}, {Slug: "hidden", Draft: true}, {Slug: "mid"}}
+	if err := generateRandomPage(cfg, tmpl, notes); err != nil {
+		t.Fatalf("generateRandomPage returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(cfg.OutputDir, "random", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `["/notes/alpha/","/notes/mid/","/notes/zeta/"]`
+	if !strings.Contains(string(data), want) {
+		t.Errorf("random page should embed the sorted note URLs %s:\n%s", want, data)
+	}
+}
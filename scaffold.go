@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// noteSkeleton is the YAML written for a new note, with placeholders for the
+// required fields and one bullet and tag, which published notes need
+const noteSkeleton = `slug: %q
+title: %q
+thesis: %q
+bullets:
+  - %q
+tags: [%q]
+date: %q
+`
+
+// scaffoldNote writes a new note with the given slug to contentDir, dated
+// date, and returns the path of the file. It refuses to overwrite an
+// existing file.
+func scaffoldNote(contentDir, slug string, date time.Time) (string, error) {
+	if !slugPattern.MatchString(slug) {
+		return "", fmt.Errorf("slug %q should only contain lowercase letters, numbers, and hyphens, with / between segments", slug)
+	}
+	path := filepath.Join(contentDir, filepath.FromSlash(slug)+".yaml")
+	if err := checkDuplicateSlugs([]Note{{Slug: slug, Source: path}}); err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		if os.IsExist(err) {
+			return "", fmt.Errorf("%s already exists", path)
+		}
+		return "", err
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, noteSkeleton, slug, "TODO: title", "TODO: the one-sentence takeaway", "TODO: a supporting point", "todo", date.Format(dateLayout))
+	return path, err
+}
+
+// runNew implements the new subcommand, which scaffolds a note in content/
+func runNew(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: notes new <slug>")
+	}
+	path, err := scaffoldNote("content", args[0], time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✓ Created %s\n", path)
+	return nil
+}
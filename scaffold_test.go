@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestScaffoldNote(t *testing.T) {
+	dir := t.TempDir()
+	date := time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)
+
+	path, err := scaffoldNote(dir, "go/concurrency", date)
+	if err != nil {
+		t.Fatalf("scaffoldNote returned error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read scaffolded note: %v", err)
+	}
+
+	var note Note
+	if err := yaml.Unmarshal(data, &note); err != nil {
+		t.Fatalf("scaffolded note does not parse: %v", err)
+	}
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validateNote(note, filepath.Join("content", rel), defaultFieldLimits); err != nil {
+		t.Errorf("scaffolded note is invalid: %v", err)
+	}
+	if note.Slug != "go/concurrency" || note.Date != "2026-03-14" {
+		t.Errorf("unexpected slug %q or date %q", note.Slug, note.Date)
+	}
+	// A scaffolded note passes the checks run on committed content
+	validateContentFile(t, testConfig(t), path)
+
+	if _, err := scaffoldNote(dir, "go/concurrency", date); err == nil {
+		t.Error("scaffoldNote should refuse to overwrite an existing note")
+	}
+	for _, slug := range []string{"Bad Slug", "tags/go"} {
+		if _, err := scaffoldNote(dir, slug, date); err == nil {
+			t.Errorf("scaffoldNote should reject slug %q", slug)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// checkSchedule returns an error unless the publish_at and expire_at dates
+// of note, when set, are valid and expire_at is after publish_at. The dates
+// are read in any date_layout by prepareNote, which stores them as YYYY-MM-DD.
+func checkSchedule(note Note) error {
+	var publish, expire time.Time
+	var err error
+	if note.PublishAt != "" {
+		if publish, err = time.Parse(dateLayout, note.PublishAt); err != nil {
+			return fmt.Errorf("parsing publish_at: %w", err)
+		}
+	}
+	if note.ExpireAt != "" {
+		if expire, err = time.Parse(dateLayout, note.ExpireAt); err != nil {
+			return fmt.Errorf("parsing expire_at: %w", err)
+		}
+	}
+	if note.PublishAt != "" && note.ExpireAt != "" && !expire.After(publish) {
+		return fmt.Errorf("expire_at %s must be after publish_at %s", note.ExpireAt, note.PublishAt)
+	}
+	return nil
+}
+
+// scheduled reports whether note is visible at now: on or after the start
+// (UTC) of its publish_at date and before the start of its expire_at date.
+// The dates must have been checked with checkSchedule.
+func scheduled(note Note, now time.Time) bool {
+	if note.PublishAt != "" {
+		publish, _ := time.Parse(dateLayout, note.PublishAt)
+		if now.Before(publish) {
+			return false
+		}
+	}
+	if note.ExpireAt != "" {
+		expire, _ := time.Parse(dateLayout, note.ExpireAt)
+		if !now.Before(expire) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestReadNotesPublishWindow(t *testing.T) {
+	fsys := fstest.MapFS{
+		"content/current.yaml":  {Data: []byte("slug: current\ntitle: Current\nthesis: Visible.\npublish_at: 2025-06-01\nexpire_at: 2025-07-01\n")},
+		"content/future.yaml":   {Data: []byte("slug: future\ntitle: Future\nthesis: Not yet.\npublish_at: 2025-06-16\n")},
+		"content/expired.yaml":  {Data: []byte("slug: expired\ntitle: Expired\nthesis: No longer.\nexpire_at: 2025-06-15\n")},
+		"content/timeless.yaml": {Data: []byte("slug: timeless\ntitle: Timeless\nthesis: Always.\n")},
+	}
+
+	cfg := testConfig(t)
+	cfg.BuildTime = time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+	notes, err := readNotes(cfg, fsys)
+	if err != nil {
+		t.Fatalf("readNotes failed: %v", err)
+	}
+
+	var slugs []string
+	for _, note := range notes {
+		slugs = append(slugs, note.Slug)
+	}
+	if got := strings.Join(slugs, ","); got != "current,timeless" {
+		t.Errorf("expected only notes inside their publish window, got %s", got)
+	}
+
+	if err := generateRSS(cfg, notes); err != nil {
+		t.Fatalf("generateRSS returned error: %v", err)
+	}
+	var rss RSS
+	readXMLFile(t, filepath.Join(cfg.OutputDir, "rss.xml"), &rss)
+	for _, item := range rss.Channel.Items {
+		if strings.Contains(item.Link, "future") || strings.Contains(item.Link, "expired") {
+			t.Errorf("feed should not list %s", item.Link)
+		}
+	}
+}
+
+func TestReadNotesPublishWindowDateLayout(t *testing.T) {
+	fsys := fstest.MapFS{
+		"content/current.yaml": {Data: []byte("slug: current\ntitle: Current\nthesis: Visible.\npublish_at: Jun 1, 2025\nexpire_at: Jul 1, 2025\n")},
+		"content/future.yaml":  {Data: []byte("slug: future\ntitle: Future\nthesis: Not yet.\npublish_at: Jun 16, 2025\n")},
+	}
+
+	cfg := testConfig(t)
+	cfg.DateLayout = "Jan 2, 2006"
+	cfg.BuildTime = time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+	notes, err := readNotes(cfg, fsys)
+	if err != nil {
+		t.Fatalf("readNotes failed: %v", err)
+	}
+	if len(notes) != 1 || notes[0].Slug != "current" {
+		t.Fatalf("expected only the current note, got %+v", notes)
+	}
+	if notes[0].PublishAt != "2025-06-01" || notes[0].ExpireAt != "2025-07-01" {
+		t.Errorf("expected schedule dates in ISO form, got %q and %q", notes[0].PublishAt, notes[0].ExpireAt)
+	}
+}
+
+func TestCheckSchedule(t *testing.T) {
+	tests := []struct {
+		note    Note
+		wantErr string
+	}{
+		{Note{PublishAt: "2025-06-01", ExpireAt: "2025-07-01"}, ""},
+		{Note{PublishAt: "June 1"}, "parsing publish_at"},
+		{Note{ExpireAt: "2025-13-01"}, "parsing expire_at"},
+		{Note{PublishAt: "2025-07-01", ExpireAt: "2025-07-01"}, "must be after publish_at"},
+	}
+	for _, tt := range tests {
+		err := checkSchedule(tt.note)
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("checkSchedule(%+v) returned error: %v", tt.note, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("checkSchedule(%+v) = %v, want an error containing %q", tt.note, err, tt.wantErr)
+		}
+	}
+}
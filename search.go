@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SearchEntry represents a single note in the client-side search index
+type SearchEntry struct {
+	Slug   string   `json:"slug"`
+	Title  string   `json:"title"`
+	Thesis string   `json:"thesis"`
+	Tags   []string `json:"tags"`
+	Text   string   `json:"text"`
+}
+
+func generateSearchIndex(outDir string, notes []Note) error {
+	published := publishedNotes(notes)
+	entries := make([]SearchEntry, 0, len(published))
+	for _, note := range published {
+		bullets := make([]string, 0, len(note.Bullets))
+		for _, bullet := range note.Bullets {
+			bullets = append(bullets, strings.TrimSpace(bullet))
+		}
+
+		tags := make([]string, 0, len(note.Tags))
+		for _, tag := range note.Tags {
+			tags = append(tags, strings.TrimSpace(tag))
+		}
+
+		entries = append(entries, SearchEntry{
+			Slug:   note.Slug,
+			Title:  strings.TrimSpace(note.Title),
+			Thesis: strings.TrimSpace(note.Thesis),
+			Tags:   tags,
+			Text:   strings.Join(bullets, " "),
+		})
+	}
+
+	// Compact encoding keeps the file small; field order follows the struct
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(outDir, "search.json"), data, 0644)
+}
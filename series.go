@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Series is a named group of notes read in order
+type Series struct {
+	Name  string
+	Slug  string
+	Notes []Note
+}
+
+// SeriesPageData holds data for the series template
+type SeriesPageData struct {
+	Lang      string
+	CSP       string
+	SiteTitle string
+	Series    Series
+	Footer    FooterData
+}
+
+// seriesPath returns the site-relative path of a series page
+func seriesPath(slug string) string {
+	return "/series/" + slug + "/"
+}
+
+// checkSeries returns an error when a note in a series has no series_order,
+// when two parts of a series share an order, or when two series names map to
+// the same path
+func checkSeries(notes []Note) error {
+	orders := make(map[string]map[int]string)
+	names := make(map[string]string)
+	for _, note := range notes {
+		if note.Series == "" {
+			continue
+		}
+		if note.SeriesOrder < 1 {
+			return fmt.Errorf("%s is in series %q but has no positive series_order", note.Source, note.Series)
+		}
+
+		slug := slugify(note.Series)
+		if slug == "" {
+			return fmt.Errorf("series %q in %s has no letters or digits to build a path from", note.Series, note.Source)
+		}
+		if name, ok := names[slug]; ok && name != note.Series {
+			return fmt.Errorf("series %q in %s and series %q share the path %s", note.Series, note.Source, name, seriesPath(slug))
+		}
+		names[slug] = note.Series
+
+		if orders[note.Series] == nil {
+			orders[note.Series] = make(map[int]string)
+		}
+		if other, ok := orders[note.Series][note.SeriesOrder]; ok {
+			return fmt.Errorf("series %q has two parts with series_order %d: %s and %s", note.Series, note.SeriesOrder, other, note.Source)
+		}
+		orders[note.Series][note.SeriesOrder] = note.Source
+	}
+	return nil
+}
+
+// buildSeries groups notes by series, sorted by series name with the parts
+// of each series in series_order
+func buildSeries(notes []Note) []Series {
+	bySlug := make(map[string]*Series)
+	for _, note := range notes {
+		if note.Series == "" {
+			continue
+		}
+		slug := slugify(note.Series)
+		if bySlug[slug] == nil {
+			bySlug[slug] = &Series{Name: note.Series, Slug: slug}
+		}
+		bySlug[slug].Notes = append(bySlug[slug].Notes, note)
+	}
+
+	series := make([]Series, 0, len(bySlug))
+	for _, s := range bySlug {
+		sort.Slice(s.Notes, func(i, j int) bool {
+			return s.Notes[i].SeriesOrder < s.Notes[j].SeriesOrder
+		})
+		series = append(series, *s)
+	}
+	sort.Slice(series, func(i, j int) bool {
+		return series[i].Slug < series[j].Slug
+	})
+	return series
+}
+
+// seriesNeighbors returns the parts before and after note within its series,
+// either of which may be nil
+func seriesNeighbors(note Note, series []Series) (prev, next *Note) {
+	if note.Series == "" {
+		return nil, nil
+	}
+	slug := slugify(note.Series)
+	for _, s := range series {
+		if s.Slug != slug {
+			continue
+		}
+		for i, part := range s.Notes {
+			if part.Slug != note.Slug {
+				continue
+			}
+			if i > 0 {
+				prev = &s.Notes[i-1]
+			}
+			if i < len(s.Notes)-1 {
+				next = &s.Notes[i+1]
+			}
+			return prev, next
+		}
+	}
+	return nil, nil
+}
+
+// generateSeriesPages writes series/<slug>/index.html listing the parts of
+// each series in order
+func generateSeriesPages(cfg Config, tmpl *template.Template, series []Series) error {
+	footer := newFooterData(cfg)
+	for _, s := range series {
+		dir := filepath.Join(cfg.OutputDir, "series", s.Slug)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+
+		data := SeriesPageData{Lang: cfg.DefaultLang, CSP: cfg.CSP, SiteTitle: cfg.SiteTitle, Series: s, Footer: footer}
+		if err := writeSeriesPage(tmpl, filepath.Join(dir, "index.html"), data); err != nil {
+			return fmt.Errorf("generating series %s: %w", s.Slug, err)
+		}
+	}
+	return nil
+}
+
+func writeSeriesPage(tmpl *template.Template, path string, data SeriesPageData) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return tmpl.Execute(f, data)
+}
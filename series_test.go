@@ -0,0 +1,122 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckSeries(t *testing.T) {
+	tests := []struct {
+		name    string
+		notes   []Note
+		wantErr string
+	}{
+		{
+			name: "valid",
+			notes: []Note{
+				{Slug: "a", Series: "Go Basics", SeriesOrder: 1, Source: "content/a.yaml"},
+				{Slug: "b", Series: "Go Basics", SeriesOrder: 2, Source: "content/b.yaml"},
+				{Slug: "c", Series: "Other", SeriesOrder: 1, Source: "content/c.yaml"},
+				{Slug: "d", Source: "content/d.yaml"},
+			},
+		},
+		{
+			name: "duplicate order",
+			notes: []Note{
+				{Slug: "a", Series: "Go Basics", SeriesOrder: 1, Source: "content/a.yaml"},
+				{Slug: "b", Series: "Go Basics", SeriesOrder: 1, Source: "content/b.yaml"},
+			},
+			wantErr: "two parts with series_order 1",
+		},
+		{
+			name: "missing order",
+			notes: []Note{
+				{Slug: "a", Series: "Go Basics", Source: "content/a.yaml"},
+			},
+			wantErr: "no positive series_order",
+		},
+		{
+			name: "colliding paths",
+			notes: []Note{
+				{Slug: "a", Series: "Go Basics", SeriesOrder: 1, Source: "content/a.yaml"},
+				{Slug: "b", Series: "go basics!", SeriesOrder: 2, Source: "content/b.yaml"},
+			},
+			wantErr: "share the path /series/go-basics/",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSeries(tt.notes)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestBuildSeriesAndNeighbors(t *testing.T) {
+	notes := []Note{
+		{Slug: "part-3", Series: "Go Basics", SeriesOrder: 3},
+		{Slug: "standalone"},
+		{Slug: "part-1", Series: "Go Basics", SeriesOrder: 1},
+		{Slug: "part-2", Series: "Go Basics", SeriesOrder: 2},
+	}
+
+	series := buildSeries(notes)
+	if len(series) != 1 {
+		t.Fatalf("expected 1 series, got %d", len(series))
+	}
+	if series[0].Slug != "go-basics" || series[0].Name != "Go Basics" {
+		t.Errorf("unexpected series %q (%s)", series[0].Name, series[0].Slug)
+	}
+	for i, want := range []string{"part-1", "part-2", "part-3"} {
+		if series[0].Notes[i].Slug != want {
+			t.Errorf("part %d = %q, want %q", i+1, series[0].Notes[i].Slug, want)
+		}
+	}
+
+	prev, next := seriesNeighbors(notes[3], series)
+	if prev == nil || prev.Slug != "part-1" || next == nil || next.Slug != "part-3" {
+		t.Errorf("unexpected neighbors of part-2: %v, %v", prev, next)
+	}
+	if prev, _ := seriesNeighbors(notes[2], series); prev != nil {
+		t.Errorf("first part should have no previous part, got %s", prev.Slug)
+	}
+	if prev, next := seriesNeighbors(notes[1], series); prev != nil || next != nil {
+		t.Error("a note outside any series should have no neighbors")
+	}
+}
+
+func TestGenerateSeriesPages(t *testing.T) {
+	tmpl, err := parseTemplate(siteFS, templateFuncs(Config{}, nil), "templates/series.html", "templates/footer.html")
+	if err != nil {
+		t.Fatalf("Failed to parse series template: %v", err)
+	}
+
+	cfg := testConfig(t)
+	series := buildSeries([]Note{
+		{Slug: "second", Title: "Second", Series: "Go Basics", SeriesOrder: 2},
+		{Slug: "first", Title: "First", Series: "Go Basics", SeriesOrder: 1},
+	})
+	if err := generateSeriesPages(cfg, tmpl, series); err != nil {
+		t.Fatalf("generateSeriesPages failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(cfg.OutputDir, "series", "go-basics", "index.html"))
+	if err != nil {
+		t.Fatalf("expected series page: %v", err)
+	}
+	html := string(content)
+	first, second := strings.Index(html, `href="/first/"`), strings.Index(html, `href="/second/"`)
+	if first < 0 || second < 0 || first > second {
+		t.Errorf("series page should list parts in order:\n%s", html)
+	}
+}
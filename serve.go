@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// previewHandler serves the generated site in outDir the way a static host
+// would. Directory requests such as /slug/ resolve to /slug/index.html, and
+// unknown paths are answered with 404.html.
+func previewHandler(outDir string) http.Handler {
+	files := http.FileServer(http.Dir(outDir))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := filepath.Join(outDir, filepath.FromSlash(path.Clean("/"+r.URL.Path)))
+		if _, err := os.Stat(name); errors.Is(err, fs.ErrNotExist) {
+			// Serve /slug as /slug.html when only the extension form exists
+			if !strings.HasSuffix(r.URL.Path, "/") {
+				if _, err := os.Stat(name + ".html"); err == nil {
+					http.ServeFile(w, r, name+".html")
+					return
+				}
+			}
+			serveNotFound(w, outDir)
+			return
+		}
+		files.ServeHTTP(w, r)
+	})
+}
+
+func serveNotFound(w http.ResponseWriter, outDir string) {
+	page, err := os.ReadFile(filepath.Join(outDir, "404.html"))
+	if err != nil {
+		http.Error(w, "404 page not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusNotFound)
+	w.Write(page)
+}
+
+// servePreview serves outDir over HTTP on port until the server fails. When
+// basePath is set the site is served beneath it, as it will be when deployed.
+func servePreview(outDir, basePath string, port int) error {
+	addr := fmt.Sprintf(":%d", port)
+	fmt.Printf("Serving %s at http://localhost%s%s/\n", outDir, addr, basePath)
+
+	handler := previewHandler(outDir)
+	if basePath != "" {
+		mux := http.NewServeMux()
+		mux.Handle(basePath+"/", http.StripPrefix(basePath, handler))
+		mux.Handle("/{$}", http.RedirectHandler(basePath+"/", http.StatusFound))
+		handler = mux
+	}
+	return http.ListenAndServe(addr, handler)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPreviewHandler(t *testing.T) {
+	outDir := t.TempDir()
+	files := map[string]string{
+		"index.html":      "home",
+		"404.html":        "missing",
+		"style.css":       "body {}",
+		"note.html":       "note",
+		"note/index.html": "note",
+	}
+	for name, content := range files {
+		path := filepath.Join(outDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		path        string
+		status      int
+		body        string
+		contentType string
+	}{
+		{"/", http.StatusOK, "home", "text/html"},
+		{"/note/", http.StatusOK, "note", "text/html"},
+		{"/note", http.StatusMovedPermanently, "", ""},
+		{"/style.css", http.StatusOK, "body {}", "text/css"},
+		{"/unknown/", http.StatusNotFound, "missing", "text/html"},
+	}
+
+	handler := previewHandler(outDir)
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if tt.body != "" && rec.Body.String() != tt.body {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.body)
+			}
+			if !strings.HasPrefix(rec.Header().Get("Content-Type"), tt.contentType) {
+				t.Errorf("Content-Type = %q, want %q", rec.Header().Get("Content-Type"), tt.contentType)
+			}
+		})
+	}
+}
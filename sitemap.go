@@ -0,0 +1,244 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// sitemapNamespace is the XML namespace for sitemaps and sitemap indexes
+const sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// xhtmlNamespace is the XML namespace for hreflang alternates in sitemaps
+const xhtmlNamespace = "http://www.w3.org/1999/xhtml"
+
+// imageNamespace is the XML namespace for Google's image sitemap extension
+const imageNamespace = "http://www.google.com/schemas/sitemap-image/1.1"
+
+// validChangeFreqs are the changefreq values allowed by the sitemap protocol
+var validChangeFreqs = map[string]bool{
+	"always": true, "hourly": true, "daily": true, "weekly": true,
+	"monthly": true, "yearly": true, "never": true,
+}
+
+// defaultSitemapMaxURLs is the protocol limit on URLs in a single sitemap file
+const defaultSitemapMaxURLs = 50000
+
+// Sitemap represents the root sitemap element
+type Sitemap struct {
+	XMLName    xml.Name     `xml:"urlset"`
+	XMLNS      string       `xml:"xmlns,attr"`
+	XMLNSXHTML string       `xml:"xmlns:xhtml,attr,omitempty"`
+	XMLNSImage string       `xml:"xmlns:image,attr,omitempty"`
+	URLs       []SitemapURL `xml:"url"`
+}
+
+// SitemapURL represents a URL in the sitemap
+type SitemapURL struct {
+	Loc        string             `xml:"loc"`
+	LastMod    string             `xml:"lastmod"`
+	ChangeFreq string             `xml:"changefreq"`
+	Priority   string             `xml:"priority"`
+	Alternates []SitemapAlternate `xml:"xhtml:link"`
+	Images     []SitemapImage     `xml:"image:image"`
+}
+
+// SitemapAlternate links a sitemap URL to a translation of the same page
+type SitemapAlternate struct {
+	Rel      string `xml:"rel,attr"`
+	HrefLang string `xml:"hreflang,attr"`
+	Href     string `xml:"href,attr"`
+}
+
+// SitemapImage lists an image shown on a sitemap URL
+type SitemapImage struct {
+	Loc string `xml:"image:loc"`
+}
+
+// SitemapIndex represents the root sitemap index element
+type SitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	XMLNS    string       `xml:"xmlns,attr"`
+	Sitemaps []SitemapRef `xml:"sitemap"`
+}
+
+// SitemapRef represents a sitemap file listed in a sitemap index
+type SitemapRef struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod"`
+}
+
+// sitemapMaxURLs returns the number of URLs per sitemap file from the
+// SITEMAP_MAX_URLS environment variable, falling back to the protocol limit
+func sitemapMaxURLs() int {
+	if n, err := strconv.Atoi(os.Getenv("SITEMAP_MAX_URLS")); err == nil && n > 0 {
+		return n
+	}
+	return defaultSitemapMaxURLs
+}
+
+// generateSitemap writes sitemap.xml. When there are more than maxURLs URLs,
+// they are split across sitemap-1.xml, sitemap-2.xml, and so on, and
+// sitemap.xml becomes a sitemap index referencing them.
+func generateSitemap(cfg Config, notes []Note, maxURLs int) error {
+	baseURL, outDir := cfg.BaseURL, cfg.OutputDir
+	lastMod := cfg.BuildTime.Format(dateLayout)
+
+	urls := make([]SitemapURL, 0, len(notes)+4)
+
+	// Add homepage, archive, theme index, and the printable page of all notes
+	urls = append(urls, SitemapURL{
+		Loc:        baseURL + "/",
+		LastMod:    lastMod,
+		ChangeFreq: cfg.HomepageChangeFreq,
+		Priority:   cfg.HomepagePriority,
+	}, SitemapURL{
+		Loc:        baseURL + "/archive/",
+		LastMod:    lastMod,
+		ChangeFreq: "weekly",
+		Priority:   "0.5",
+	}, SitemapURL{
+		Loc:        baseURL + "/themes/",
+		LastMod:    lastMod,
+		ChangeFreq: "weekly",
+		Priority:   "0.5",
+	}, SitemapURL{
+		Loc:        baseURL + "/all/",
+		LastMod:    lastMod,
+		ChangeFreq: "weekly",
+		Priority:   "0.2",
+	})
+
+	// Add the remaining index pages
+	for page := 2; page <= pageCount(len(notes), cfg.PageSize); page++ {
+		urls = append(urls, SitemapURL{
+			Loc:        baseURL + pagePath(page),
+			LastMod:    lastMod,
+			ChangeFreq: "weekly",
+			Priority:   "0.5",
+		})
+	}
+
+	// Add a page for each category with notes that may be indexed
+	for _, category := range buildCategories(indexableNotes(notes)) {
+		urls = append(urls, SitemapURL{
+			Loc:        baseURL + categoryPath(category.Slug),
+			LastMod:    lastMod,
+			ChangeFreq: "weekly",
+			Priority:   "0.5",
+		})
+	}
+
+	// Add individual notes that may be indexed, using the commit or note dates when available
+	for _, note := range indexableNotes(notes) {
+		noteLastMod := lastMod
+		if note.GitModified != "" {
+			noteLastMod = note.GitModified
+		} else if modified := lastModified(note); modified != "" {
+			noteLastMod = modified
+		}
+		url := SitemapURL{
+			Loc:        noteCanonicalURL(cfg, note.Slug),
+			LastMod:    noteLastMod,
+			ChangeFreq: cfg.ChangeFreq,
+			Priority:   cfg.Priority,
+		}
+		if note.ChangeFreq != "" {
+			url.ChangeFreq = note.ChangeFreq
+		}
+		if note.Priority != "" {
+			url.Priority = note.Priority
+		}
+		if note.Image != "" {
+			url.Images = []SitemapImage{{Loc: absoluteURL(baseURL, note.Image)}}
+		}
+		for _, alt := range noteAlternates(cfg, note) {
+			url.Alternates = append(url.Alternates, SitemapAlternate{Rel: "alternate", HrefLang: alt.Lang, Href: alt.URL})
+		}
+		urls = append(urls, url)
+	}
+
+	if len(urls) <= maxURLs {
+		return writeSitemapFile(filepath.Join(outDir, "sitemap.xml"), urls)
+	}
+
+	index := SitemapIndex{XMLNS: sitemapNamespace}
+	for i := 0; i*maxURLs < len(urls); i++ {
+		end := min((i+1)*maxURLs, len(urls))
+		name := fmt.Sprintf("sitemap-%d.xml", i+1)
+		if err := writeSitemapFile(filepath.Join(outDir, name), urls[i*maxURLs:end]); err != nil {
+			return err
+		}
+		index.Sitemaps = append(index.Sitemaps, SitemapRef{
+			Loc:     fmt.Sprintf("%s/%s", baseURL, name),
+			LastMod: lastMod,
+		})
+	}
+	return writeXMLFile(filepath.Join(outDir, "sitemap.xml"), index)
+}
+
+// validateSitemapHints returns an error if changefreq is not an allowed
+// sitemap value or priority is not between 0.0 and 1.0. Empty values are valid.
+func validateSitemapHints(changeFreq, priority string) error {
+	if changeFreq != "" && !validChangeFreqs[changeFreq] {
+		return fmt.Errorf("invalid changefreq %q, should be one of always, hourly, daily, weekly, monthly, yearly, or never", changeFreq)
+	}
+	if priority != "" {
+		p, err := strconv.ParseFloat(priority, 64)
+		if err != nil || p < 0 || p > 1 {
+			return fmt.Errorf("invalid priority %q, should be between 0.0 and 1.0", priority)
+		}
+	}
+	return nil
+}
+
+// writeSitemapFile writes a single sitemap containing urls to path
+func writeSitemapFile(path string, urls []SitemapURL) error {
+	sitemap := Sitemap{
+		XMLNS: sitemapNamespace,
+		URLs:  urls,
+	}
+	for _, url := range urls {
+		if len(url.Alternates) > 0 {
+			sitemap.XMLNSXHTML = xhtmlNamespace
+		}
+		if len(url.Images) > 0 {
+			sitemap.XMLNSImage = imageNamespace
+		}
+	}
+	return writeXMLFile(path, sitemap)
+}
+
+// writeXMLFile writes v to path as indented XML with an XML header
+func writeXMLFile(path string, v any) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// Write XML header
+	if _, err := f.WriteString(xml.Header); err != nil {
+		return err
+	}
+
+	// Write XML with proper encoding
+	encoder := xml.NewEncoder(f)
+	encoder.Indent("", "  ")
+	return encoder.Encode(v)
+}
+
+// generateRobots writes robots.txt referencing the sitemap. Setting the
+// ROBOTS_DISALLOW environment variable to 1 blocks all crawlers, which is
+// useful for staging and preview builds.
+func generateRobots(cfg Config) error {
+	rule := "Allow: /"
+	if os.Getenv("ROBOTS_DISALLOW") == "1" {
+		rule = "Disallow: /"
+	}
+
+	content := fmt.Sprintf("User-agent: *\n%s\n\nSitemap: %s/sitemap.xml\n", rule, cfg.BaseURL)
+	return os.WriteFile(filepath.Join(cfg.OutputDir, "robots.txt"), []byte(content), 0644)
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateSitemapSplitsIntoIndex(t *testing.T) {
+	cfg := testConfig(t)
+	outDir := cfg.OutputDir
+
+	// The homepage, archive, theme index, all notes page, and 5 notes produce
+	// 9 URLs, split 3 + 3 + 3
+	var notes []Note
+	for i := 0; i < 5; i++ {
+		notes = append(notes, Note{Slug: fmt.Sprintf("note-%d", i)})
+	}
+	if err := generateSitemap(cfg, notes, 3); err != nil {
+		t.Fatalf("generateSitemap returned error: %v", err)
+	}
+
+	var index SitemapIndex
+	readXMLFile(t, filepath.Join(outDir, "sitemap.xml"), &index)
+	if len(index.Sitemaps) != 3 {
+		t.Fatalf("expected 3 sitemaps in the index, got %d", len(index.Sitemaps))
+	}
+
+	total := 0
+	for i, ref := range index.Sitemaps {
+		name := fmt.Sprintf("sitemap-%d.xml", i+1)
+		if ref.Loc != "https://notes.example.com/"+name {
+			t.Errorf("sitemap %d has loc %q", i+1, ref.Loc)
+		}
+		var sitemap Sitemap
+		readXMLFile(t, filepath.Join(outDir, name), &sitemap)
+		if len(sitemap.URLs) > 3 {
+			t.Errorf("%s has %d URLs, exceeding the threshold", name, len(sitemap.URLs))
+		}
+		total += len(sitemap.URLs)
+	}
+	if total != 9 {
+		t.Errorf("expected 9 URLs across all sitemaps, got %d", total)
+	}
+}
+
+func TestGenerateSitemapSingleFile(t *testing.T) {
+	cfg := testConfig(t)
+	outDir := cfg.OutputDir
+
+	if err := generateSitemap(cfg, []Note{{Slug: "only"}}, defaultSitemapMaxURLs); err != nil {
+		t.Fatalf("generateSitemap returned error: %v", err)
+	}
+
+	var sitemap Sitemap
+	readXMLFile(t, filepath.Join(outDir, "sitemap.xml"), &sitemap)
+	if len(sitemap.URLs) != 5 {
+		t.Fatalf("expected 5 URLs, got %d", len(sitemap.URLs))
+	}
+	if sitemap.URLs[1].Loc != "https://notes.example.com/archive/" {
+		t.Errorf("expected the archive page in the sitemap, got %q", sitemap.URLs[1].Loc)
+	}
+	if sitemap.URLs[2].Loc != "https://notes.example.com/themes/" {
+		t.Errorf("expected the theme index in the sitemap, got %q", sitemap.URLs[2].Loc)
+	}
+	if all := sitemap.URLs[3]; all.Loc != "https://notes.example.com/all/" || all.Priority != "0.2" {
+		t.Errorf("expected the all notes page with a low priority, got %+v", all)
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "sitemap-1.xml")); err == nil {
+		t.Error("sitemap-1.xml should not be written below the threshold")
+	}
+}
+
+func readXMLFile(t *testing.T, path string, v any) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	if err := xml.Unmarshal(data, v); err != nil {
+		t.Fatalf("Failed to parse %s: %v", path, err)
+	}
+}
+
+func TestValidateSitemapHints(t *testing.T) {
+	valid := [][2]string{{"", ""}, {"weekly", "0.5"}, {"never", "0"}, {"always", "1.0"}}
+	for _, v := range valid {
+		if err := validateSitemapHints(v[0], v[1]); err != nil {
+			t.Errorf("validateSitemapHints(%q, %q) returned error: %v", v[0], v[1], err)
+		}
+	}
+
+	invalid := [][2]string{{"sometimes", ""}, {"", "1.5"}, {"", "-0.1"}, {"", "high"}}
+	for _, v := range invalid {
+		if err := validateSitemapHints(v[0], v[1]); err == nil {
+			t.Errorf("validateSitemapHints(%q, %q) should return an error", v[0], v[1])
+		}
+	}
+}
+
+func TestGenerateSitemapIncludesIndexPages(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.PageSize = 2
+
+	var notes []Note
+	for i := 0; i < 5; i++ {
+		notes = append(notes, Note{Slug: fmt.Sprintf("note-%d", i)})
+	}
+	if err := generateSitemap(cfg, notes, defaultSitemapMaxURLs); err != nil {
+		t.Fatalf("generateSitemap returned error: %v", err)
+	}
+
+	var sitemap Sitemap
+	readXMLFile(t, filepath.Join(cfg.OutputDir, "sitemap.xml"), &sitemap)
+	locs := make(map[string]bool)
+	for _, u := range sitemap.URLs {
+		locs[u.Loc] = true
+	}
+	for _, want := range []string{"https://notes.example.com/page/2/", "https://notes.example.com/page/3/"} {
+		if !locs[want] {
+			t.Errorf("sitemap missing index page %s", want)
+		}
+	}
+	if locs["https://notes.example.com/page/1/"] || locs["https://notes.example.com/page/4/"] {
+		t.Error("sitemap should only list index pages after the first that exist")
+	}
+}
+
+func TestGenerateSitemapHomepageHints(t *testing.T) {
+	t.Setenv("BASEURL", "https://notes.example.com")
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("homepage_changefreq: yearly\nhomepage_priority: \"0.6\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("loadConfig returned error: %v", err)
+	}
+	cfg.OutputDir = t.TempDir()
+
+	if err := generateSitemap(cfg, nil, defaultSitemapMaxURLs); err != nil {
+		t.Fatalf("generateSitemap returned error: %v", err)
+	}
+	var sitemap Sitemap
+	readXMLFile(t, filepath.Join(cfg.OutputDir, "sitemap.xml"), &sitemap)
+	if home := sitemap.URLs[0]; home.ChangeFreq != "yearly" || home.Priority != "0.6" {
+		t.Errorf("expected the configured homepage hints, got %+v", home)
+	}
+
+	for _, data := range []string{"homepage_changefreq: sometimes\n", "homepage_priority: \"1.5\"\n"} {
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := loadConfig(path); err == nil {
+			t.Errorf("expected an error for %q", data)
+		}
+	}
+}
+
+func TestGenerateSitemapExcludesNoIndex(t *testing.T) {
+	cfg := testConfig(t)
+	notes := []Note{{Slug: "listed"}, {Slug: "hidden", NoIndex: true}, {Slug: "draft", Draft: true}}
+
+	if err := generateSitemap(cfg, notes, defaultSitemapMaxURLs); err != nil {
+		t.Fatalf("generateSitemap returned error: %v", err)
+	}
+
+	var sitemap Sitemap
+	readXMLFile(t, filepath.Join(cfg.OutputDir, "sitemap.xml"), &sitemap)
+	for _, u := range sitemap.URLs {
+		if u.Loc == "https://notes.example.com/hidden/" || u.Loc == "https://notes.example.com/draft/" {
+			t.Errorf("sitemap should not list %s", u.Loc)
+		}
+	}
+	if len(sitemap.URLs) != 5 {
+		t.Errorf("expected the homepage, archive, theme index, all notes page, and listed note, got %d URLs", len(sitemap.URLs))
+	}
+}
+
+func TestGenerateSitemapIncludesCategoryPages(t *testing.T) {
+	cfg := testConfig(t)
+	notes := []Note{{Slug: "a", Category: "Engineering"}, {Slug: "b", Category: "Hidden", NoIndex: true}}
+
+	if err := generateSitemap(cfg, notes, defaultSitemapMaxURLs); err != nil {
+		t.Fatalf("generateSitemap returned error: %v", err)
+	}
+
+	var sitemap Sitemap
+	readXMLFile(t, filepath.Join(cfg.OutputDir, "sitemap.xml"), &sitemap)
+	locs := make(map[string]bool)
+	for _, u := range sitemap.URLs {
+		locs[u.Loc] = true
+	}
+	if !locs["https://notes.example.com/category/engineering/"] {
+		t.Errorf("sitemap should list the category page, got %v", locs)
+	}
+	if locs["https://notes.example.com/category/hidden/"] {
+		t.Error("sitemap should not list categories without indexable notes")
+	}
+}
+
+func TestGenerateSitemapNestedSlug(t *testing.T) {
+	cfg := testConfig(t)
+	if err := generateSitemap(cfg, []Note{{Slug: "go/concurrency"}}, defaultSitemapMaxURLs); err != nil {
+		t.Fatalf("generateSitemap returned error: %v", err)
+	}
+
+	var sitemap Sitemap
+	readXMLFile(t, filepath.Join(cfg.OutputDir, "sitemap.xml"), &sitemap)
+	if last := sitemap.URLs[len(sitemap.URLs)-1].Loc; last != "https://notes.example.com/go/concurrency/" {
+		t.Errorf("expected the nested note URL in the sitemap, got %q", last)
+	}
+}
+
+func TestGenerateSitemapImages(t *testing.T) {
+	cfg := testConfig(t)
+	notes := []Note{{Slug: "pictured", Image: "/images/cover.png"}, {Slug: "plain"}}
+	if err := generateSitemap(cfg, notes, defaultSitemapMaxURLs); err != nil {
+		t.Fatalf("generateSitemap returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(cfg.OutputDir, "sitemap.xml"))
+	if err != nil {
+		t.Fatalf("Failed to read sitemap: %v", err)
+	}
+	for _, want := range []string{
+		`xmlns:image="http://www.google.com/schemas/sitemap-image/1.1"`,
+		`<image:image><image:loc>https://notes.example.com/images/cover.png</image:loc></image:image>`,
+	} {
+		if !strings.Contains(strings.Join(strings.Fields(string(data)), ""), strings.Join(strings.Fields(want), "")) {
+			t.Errorf("sitemap does not contain %s:\n%s", want, data)
+		}
+	}
+	if n := strings.Count(string(data), "<image:image>"); n != 1 {
+		t.Errorf("expected an image entry only for the note with an image, got %d", n)
+	}
+}
@@ -0,0 +1,10 @@
+// Copy a bullet's link when its anchor is clicked, still jumping to it
+const bullets = document.querySelector('.detail-bullets');
+if (bullets) {
+    bullets.addEventListener('click', function (event) {
+        const link = event.target.closest('.bullet-link');
+        if (link && navigator.clipboard) {
+            navigator.clipboard.writeText(link.href);
+        }
+    });
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// TagCount represents a tag and the number of notes using it
+type TagCount struct {
+	Tag   string
+	Count int
+}
+
+// normalizeTag returns the canonical form of a tag used for counting and grouping
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// tagLookup maps the normalized form of every canonical tag and alias in
+// rules, which relates each canonical tag to its aliases, to the canonical tag
+func tagLookup(rules map[string][]string) map[string]string {
+	lookup := make(map[string]string)
+	for canonical, aliases := range rules {
+		canonical = strings.TrimSpace(canonical)
+		lookup[normalizeTag(canonical)] = canonical
+		for _, alias := range aliases {
+			lookup[normalizeTag(alias)] = canonical
+		}
+	}
+	return lookup
+}
+
+// validateTagRules returns an error when a tag is listed as an alias of more
+// than one canonical tag, or as both an alias and a canonical tag
+func validateTagRules(rules map[string][]string) error {
+	owners := make(map[string]string)
+	for canonical := range rules {
+		owners[normalizeTag(canonical)] = canonical
+	}
+	for canonical, aliases := range rules {
+		for _, alias := range aliases {
+			key := normalizeTag(alias)
+			if owner, ok := owners[key]; ok && owner != canonical {
+				return fmt.Errorf("tag alias %q of %q is already used by %q", alias, canonical, owner)
+			}
+			owners[key] = canonical
+		}
+	}
+	return nil
+}
+
+// resolveTags replaces each alias in tags with its canonical tag from rules,
+// dropping tags that become duplicates. When restrict is set, a tag that is
+// neither a canonical tag nor an alias is an error.
+func resolveTags(tags []string, rules map[string][]string, restrict bool) ([]string, error) {
+	if len(rules) == 0 && !restrict {
+		return tags, nil
+	}
+
+	lookup := tagLookup(rules)
+	resolved := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		if canonical, ok := lookup[normalizeTag(tag)]; ok {
+			tag = canonical
+		} else if restrict {
+			return nil, fmt.Errorf("tag %q is not in the tags allowlist", tag)
+		}
+		if !seen[normalizeTag(tag)] {
+			seen[normalizeTag(tag)] = true
+			resolved = append(resolved, tag)
+		}
+	}
+	return resolved, nil
+}
+
+// countTags returns every tag used by notes with its occurrence count, sorted by tag.
+// Tags are case-normalized so "Go" and "go" are counted together.
+func countTags(notes []Note) []TagCount {
+	counts := make(map[string]int)
+	for _, note := range notes {
+		for _, tag := range note.Tags {
+			counts[normalizeTag(tag)]++
+		}
+	}
+
+	tags := make([]TagCount, 0, len(counts))
+	for tag, count := range counts {
+		tags = append(tags, TagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Tag < tags[j].Tag
+	})
+	return tags
+}
+
+// relatedNoteCount is the default number of related notes shown on a note page
+const relatedNoteCount = 3
+
+// relatedNotes returns up to n notes sharing tags with note, ranked by the
+// number of shared tags. Ties keep the order of notes. Notes without any shared
+// tags and the note itself are never included.
+func relatedNotes(note Note, notes []Note, n int) []Note {
+	tags := make(map[string]bool, len(note.Tags))
+	for _, tag := range note.Tags {
+		tags[normalizeTag(tag)] = true
+	}
+
+	type candidate struct {
+		note   Note
+		shared int
+	}
+	var candidates []candidate
+	for _, other := range notes {
+		if other.Slug == note.Slug {
+			continue
+		}
+		shared := 0
+		for _, tag := range other.Tags {
+			if tags[normalizeTag(tag)] {
+				shared++
+			}
+		}
+		if shared > 0 {
+			candidates = append(candidates, candidate{note: other, shared: shared})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].shared > candidates[j].shared
+	})
+
+	related := make([]Note, 0, n)
+	for i := 0; i < len(candidates) && i < n; i++ {
+		related = append(related, candidates[i].note)
+	}
+	return related
+}
+
+// TagPageData holds data for the tag template
+type TagPageData struct {
+	Lang      string
+	CSP       string
+	SiteTitle string
+	Tag       string
+	Notes     []Note
+	FeedURL   string
+	Footer    FooterData
+}
+
+// tagPath returns the site-relative path of the page for tag
+func tagPath(tag string) string {
+	return "/tags/" + slugify(normalizeTag(tag)) + "/"
+}
+
+// checkTags returns an error when a tag has no letters or digits to build a
+// path from or when two different tags share a path
+func checkTags(notes []Note) error {
+	paths := make(map[string]string)
+	for _, note := range notes {
+		for _, tag := range note.Tags {
+			tag = normalizeTag(tag)
+			if slugify(tag) == "" {
+				return fmt.Errorf("tag %q in %s has no letters or digits to build a path from", tag, note.Source)
+			}
+			p := tagPath(tag)
+			if other, ok := paths[p]; ok && other != tag {
+				return fmt.Errorf("tags %q and %q in %s share the path %s", other, tag, note.Source, p)
+			}
+			paths[p] = tag
+		}
+	}
+	return nil
+}
+
+// notesWithTag returns the notes tagged with tag, compared after normalizing
+func notesWithTag(notes []Note, tag string) []Note {
+	var tagged []Note
+	for _, note := range notes {
+		for _, t := range note.Tags {
+			if normalizeTag(t) == tag {
+				tagged = append(tagged, note)
+				break
+			}
+		}
+	}
+	return tagged
+}
+
+// generateTagPages writes tags/<tag>/index.html listing the published notes
+// with each tag, and tags/<tag>/rss.xml with a feed of just those notes
+func generateTagPages(cfg Config, tmpl *template.Template, notes []Note) error {
+	published := publishedNotes(notes)
+	footer := newFooterData(cfg)
+	for _, tc := range countTags(published) {
+		p := tagPath(tc.Tag)
+		dir := filepath.Join(cfg.OutputDir, filepath.FromSlash(p))
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+
+		tagged := notesWithTag(published, tc.Tag)
+		data := TagPageData{
+			Lang:      cfg.DefaultLang,
+			CSP:       cfg.CSP,
+			SiteTitle: cfg.SiteTitle,
+			Tag:       tc.Tag,
+			Notes:     tagged,
+			FeedURL:   p + "rss.xml",
+			Footer:    footer,
+		}
+		if err := writeTagPage(tmpl, filepath.Join(dir, "index.html"), data); err != nil {
+			return fmt.Errorf("generating tag page for %s: %w", tc.Tag, err)
+		}
+
+		channel := Channel{
+			Title:       fmt.Sprintf("%s · %s", cfg.SiteTitle, tc.Tag),
+			Link:        cfg.BaseURL + p,
+			Description: fmt.Sprintf("Notes tagged %s", tc.Tag),
+		}
+		if err := writeRSSFeed(cfg, filepath.Join(dir, "rss.xml"), channel, tagged); err != nil {
+			return fmt.Errorf("generating RSS feed for tag %s: %w", tc.Tag, err)
+		}
+	}
+	return nil
+}
+
+func writeTagPage(tmpl *template.Template, path string, data TagPageData) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return tmpl.Execute(f, data)
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestCountTags(t *testing.T) {
+	notes := []Note{
+		{Slug: "a", Tags: []string{"Go", "testing"}},
+		{Slug: "b", Tags: []string{"go", " Systems "}},
+		{Slug: "c", Tags: []string{"GO"}},
+	}
+
+	want := []TagCount{
+		{Tag: "go", Count: 3},
+		{Tag: "systems", Count: 1},
+		{Tag: "testing", Count: 1},
+	}
+	if got := countTags(notes); !reflect.DeepEqual(got, want) {
+		t.Errorf("countTags() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRelatedNotes(t *testing.T) {
+	note := Note{Slug: "self", Tags: []string{"go", "testing", "design"}}
+	notes := []Note{
+		note,
+		{Slug: "one-shared", Tags: []string{"Go"}},
+		{Slug: "none-shared", Tags: []string{"rust"}},
+		{Slug: "two-shared", Tags: []string{"testing", "design"}},
+		{Slug: "also-one", Tags: []string{"design"}},
+		{Slug: "three-shared", Tags: []string{"go", "testing", "design"}},
+	}
+
+	got := relatedNotes(note, notes, 3)
+
+	var slugs []string
+	for _, n := range got {
+		slugs = append(slugs, n.Slug)
+	}
+	want := []string{"three-shared", "two-shared", "one-shared"}
+	if !reflect.DeepEqual(slugs, want) {
+		t.Errorf("relatedNotes() = %v, want %v", slugs, want)
+	}
+}
+
+func TestCheckTags(t *testing.T) {
+	if err := checkTags([]Note{{Slug: "a", Tags: []string{"Go", "go", "Site Reliability"}}}); err != nil {
+		t.Errorf("expected case variants of a tag to share a page, got %v", err)
+	}
+	if err := checkTags([]Note{{Slug: "a", Tags: []string{"c++", "c"}, Source: "content/a.yaml"}}); err == nil || !strings.Contains(err.Error(), "/tags/c/") {
+		t.Errorf("expected an error for tags sharing a path, got %v", err)
+	}
+	if err := checkTags([]Note{{Slug: "a", Tags: []string{"++"}, Source: "content/a.yaml"}}); err == nil {
+		t.Error("expected an error for a tag without letters or digits")
+	}
+}
+
+func TestGenerateTagPages(t *testing.T) {
+	tmpl, err := parseTemplate(siteFS, templateFuncs(Config{}, nil), "templates/tag.html", "templates/footer.html")
+	if err != nil {
+		t.Fatalf("Failed to parse tag template: %v", err)
+	}
+
+	cfg := testConfig(t)
+	notes := []Note{
+		{Slug: "go-note", Title: "Go Note", Tags: []string{"Go", "Testing"}},
+		{Slug: "rust-note", Title: "Rust Note", Tags: []string{"rust"}},
+		{Slug: "hidden-go", Title: "Hidden Go", Tags: []string{"go"}, NoIndex: true},
+	}
+	if err := generateTagPages(cfg, tmpl, notes); err != nil {
+		t.Fatalf("generateTagPages failed: %v", err)
+	}
+
+	page, err := os.ReadFile(filepath.Join(cfg.OutputDir, "tags", "go", "index.html"))
+	if err != nil {
+		t.Fatalf("expected tag page: %v", err)
+	}
+	if !strings.Contains(string(page), `<link rel="alternate" type="application/rss+xml" title="UnitVectorY-Labs Notes · go" href="/tags/go/rss.xml">`) {
+		t.Errorf("tag page should link to its feed:\n%s", page)
+	}
+	if !strings.Contains(string(page), `href="/hidden-go/"`) || strings.Contains(string(page), "/rust-note/") {
+		t.Errorf("tag page should list every published note with the tag and no others:\n%s", page)
+	}
+
+	var rss RSS
+	readXMLFile(t, filepath.Join(cfg.OutputDir, "tags", "go", "rss.xml"), &rss)
+	if rss.Channel.Link != "https://notes.example.com/tags/go/" {
+		t.Errorf("unexpected channel link %q", rss.Channel.Link)
+	}
+	if len(rss.Channel.Items) != 1 || rss.Channel.Items[0].Link != "https://notes.example.com/go-note/" {
+		t.Errorf("tag feed should only contain indexable notes with the tag, got %+v", rss.Channel.Items)
+	}
+}
+
+func TestResolveTags(t *testing.T) {
+	rules := map[string][]string{"go": {"golang", "Go-Lang"}, "testing": nil}
+
+	got, err := resolveTags([]string{"Golang", "testing", "go", "go-lang"}, rules, true)
+	if err != nil {
+		t.Fatalf("resolveTags returned error: %v", err)
+	}
+	if want := []string{"go", "testing"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("resolveTags = %v, want %v", got, want)
+	}
+
+	if _, err := resolveTags([]string{"go", "rust"}, rules, true); err == nil || !strings.Contains(err.Error(), `"rust"`) {
+		t.Errorf("expected an error naming the unlisted tag, got %v", err)
+	}
+	got, err = resolveTags([]string{"golang", "rust"}, rules, false)
+	if err != nil {
+		t.Fatalf("resolveTags returned error without restriction: %v", err)
+	}
+	if want := []string{"go", "rust"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("resolveTags = %v, want %v", got, want)
+	}
+}
+
+func TestValidateTagRules(t *testing.T) {
+	if err := validateTagRules(map[string][]string{"go": {"golang"}, "testing": {"tests"}}); err != nil {
+		t.Errorf("expected valid rules, got %v", err)
+	}
+	if err := validateTagRules(map[string][]string{"go": {"golang"}, "golang-tools": {"Golang"}}); err == nil {
+		t.Error("expected an error for an alias shared by two tags")
+	}
+	if err := validateTagRules(map[string][]string{"go": {"testing"}, "testing": nil}); err == nil {
+		t.Error("expected an error for an alias that is also a canonical tag")
+	}
+}
+
+func TestReadNotesResolvesTagAliases(t *testing.T) {
+	fsys := fstest.MapFS{
+		"content/first.yaml":  {Data: []byte("slug: first\ntitle: First\nthesis: One.\ntags: [golang]\n")},
+		"content/second.yaml": {Data: []byte("slug: second\ntitle: Second\nthesis: Two.\ntags: [go]\n")},
+	}
+	cfg := testConfig(t)
+	cfg.Tags = map[string][]string{"go": {"golang"}}
+
+	notes, err := readNotes(cfg, fsys)
+	if err != nil {
+		t.Fatalf("readNotes failed: %v", err)
+	}
+	if want := []TagCount{{Tag: "go", Count: 2}}; !reflect.DeepEqual(countTags(notes), want) {
+		t.Errorf("countTags = %v, want %v", countTags(notes), want)
+	}
+
+	cfg.RestrictTags = true
+	fsys["content/third.yaml"] = &fstest.MapFile{Data: []byte("slug: third\ntitle: Third\nthesis: Three.\ntags: [rust]\n")}
+	if _, err := readNotes(cfg, fsys); err == nil || !strings.Contains(err.Error(), "content/third.yaml") {
+		t.Errorf("expected an error naming the file with an unlisted tag, got %v", err)
+	}
+}
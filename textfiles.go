@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// HumansConfig holds the credits written to humans.txt
+type HumansConfig struct {
+	Team   []HumansMember `yaml:"team"`
+	Thanks []string       `yaml:"thanks"`
+}
+
+// HumansMember is a person credited in the team section of humans.txt
+type HumansMember struct {
+	Name     string `yaml:"name"`
+	Role     string `yaml:"role"`
+	Contact  string `yaml:"contact"`
+	Location string `yaml:"location"`
+}
+
+// SecurityConfig holds the fields written to .well-known/security.txt as
+// described in RFC 9116
+type SecurityConfig struct {
+	Contact            []string `yaml:"contact"`
+	Expires            string   `yaml:"expires"`
+	PreferredLanguages string   `yaml:"preferred_languages"`
+	Policy             string   `yaml:"policy"`
+	Acknowledgments    string   `yaml:"acknowledgments"`
+}
+
+// securityExpires parses the expires field as a date or an RFC 3339 time
+func securityExpires(expires string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, expires); err == nil {
+		return t, nil
+	}
+	return time.Parse(dateLayout, expires)
+}
+
+// validateSecurity returns an error unless security has the contact and
+// expires fields RFC 9116 requires
+func validateSecurity(security *SecurityConfig) error {
+	if security == nil {
+		return nil
+	}
+	if len(security.Contact) == 0 {
+		return errors.New("security.contact must list at least one contact URI")
+	}
+	if _, err := securityExpires(security.Expires); err != nil {
+		return fmt.Errorf("security.expires %q should be a YYYY-MM-DD date or RFC 3339 time", security.Expires)
+	}
+	return nil
+}
+
+// generateHumansTxt writes humans.txt crediting the people in cfg.Humans
+func generateHumansTxt(cfg Config) error {
+	var b strings.Builder
+	b.WriteString("/* TEAM */\n")
+	for _, member := range cfg.Humans.Team {
+		fmt.Fprintf(&b, "Name: %s\n", member.Name)
+		for _, field := range []struct{ label, value string }{
+			{"Role", member.Role},
+			{"Contact", member.Contact},
+			{"Location", member.Location},
+		} {
+			if field.value != "" {
+				fmt.Fprintf(&b, "%s: %s\n", field.label, field.value)
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	if len(cfg.Humans.Thanks) > 0 {
+		b.WriteString("/* THANKS */\n")
+		for _, name := range cfg.Humans.Thanks {
+			fmt.Fprintf(&b, "Name: %s\n", name)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("/* SITE */\n")
+	fmt.Fprintf(&b, "Last update: %s\n", cfg.BuildTime.Format("2006/01/02"))
+	b.WriteString("Standards: HTML5, CSS3\n")
+	b.WriteString("Software: Go\n")
+	return os.WriteFile(filepath.Join(cfg.OutputDir, "humans.txt"), []byte(b.String()), 0644)
+}
+
+// generateSecurityTxt writes .well-known/security.txt from cfg.Security
+func generateSecurityTxt(cfg Config) error {
+	expires, err := securityExpires(cfg.Security.Expires)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	for _, contact := range cfg.Security.Contact {
+		fmt.Fprintf(&b, "Contact: %s\n", contact)
+	}
+	fmt.Fprintf(&b, "Expires: %s\n", expires.UTC().Format(time.RFC3339))
+	for _, field := range []struct{ label, value string }{
+		{"Preferred-Languages", cfg.Security.PreferredLanguages},
+		{"Policy", cfg.Security.Policy},
+		{"Acknowledgments", cfg.Security.Acknowledgments},
+	} {
+		if field.value != "" {
+			fmt.Fprintf(&b, "%s: %s\n", field.label, field.value)
+		}
+	}
+	fmt.Fprintf(&b, "Canonical: %s/.well-known/security.txt\n", cfg.BaseURL)
+
+	dir := filepath.Join(cfg.OutputDir, ".well-known")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "security.txt"), []byte(b.String()), 0644)
+}
+
+// generateLLMsTxt writes llms.txt, a Markdown index of the site for language
+// models following the llms.txt convention, listing each note that may be
+// indexed with its URL and thesis
+func generateLLMsTxt(cfg Config, notes []Note) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", cfg.SiteTitle)
+	if cfg.Description != "" {
+		fmt.Fprintf(&b, "> %s\n\n", cfg.Description)
+	}
+	b.WriteString("## Notes\n\n")
+	for _, note := range indexableNotes(notes) {
+		thesis := strings.Join(strings.Fields(note.Thesis), " ")
+		fmt.Fprintf(&b, "- [%s](%s): %s\n", note.Title, noteCanonicalURL(cfg, note.Slug), thesis)
+	}
+	return os.WriteFile(filepath.Join(cfg.OutputDir, "llms.txt"), []byte(b.String()), 0644)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateHumansTxt(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.BuildTime = time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC)
+	cfg.Humans = &HumansConfig{
+		Team:   []HumansMember{{Name: "Ada", Role: "Author", Contact: "ada@example.com"}},
+		Thanks: []string{"Grace"},
+	}
+	if err := generateHumansTxt(cfg); err != nil {
+		t.Fatalf("generateHumansTxt returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(cfg.OutputDir, "humans.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"/* TEAM */\nName: Ada\nRole: Author\nContact: ada@example.com\n",
+		"/* THANKS */\nName: Grace\n",
+		"Last update: 2025/03/04\n",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("humans.txt does not contain %q:\n%s", want, data)
+		}
+	}
+	if strings.Contains(string(data), "Location") {
+		t.Error("humans.txt should omit empty fields")
+	}
+}
+
+func TestGenerateSecurityTxt(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Security = &SecurityConfig{
+		Contact:            []string{"mailto:security@example.com"},
+		Expires:            "2026-01-31",
+		PreferredLanguages: "en",
+	}
+	if err := generateSecurityTxt(cfg); err != nil {
+		t.Fatalf("generateSecurityTxt returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(cfg.OutputDir, ".well-known", "security.txt"))
+	if err != nil {
+		t.Fatalf("expected security.txt under .well-known: %v", err)
+	}
+	want := "Contact: mailto:security@example.com\n" +
+		"Expires: 2026-01-31T00:00:00Z\n" +
+		"Preferred-Languages: en\n" +
+		"Canonical: https://notes.example.com/.well-known/security.txt\n"
+	if string(data) != want {
+		t.Errorf("security.txt = %q, want %q", data, want)
+	}
+}
+
+func TestValidateSecurity(t *testing.T) {
+	tests := []struct {
+		name     string
+		security *SecurityConfig
+		wantErr  bool
+	}{
+		{"absent", nil, false},
+		{"date", &SecurityConfig{Contact: []string{"mailto:a@example.com"}, Expires: "2026-01-31"}, false},
+		{"time", &SecurityConfig{Contact: []string{"mailto:a@example.com"}, Expires: "2026-01-31T12:00:00+02:00"}, false},
+		{"no contact", &SecurityConfig{Expires: "2026-01-31"}, true},
+		{"no expires", &SecurityConfig{Contact: []string{"mailto:a@example.com"}}, true},
+	}
+	for _, tt := range tests {
+		if err := validateSecurity(tt.security); (err != nil) != tt.wantErr {
+			t.Errorf("%s: validateSecurity() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestGenerateLLMsTxt(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.SiteTitle = "Example Notes"
+	cfg.Description = "Notes on examples"
+	notes := []Note{
+		{Slug: "first", Title: "First", Thesis: "Spans\n  two lines."},
+		{Slug: "hidden", Title: "Hidden", Thesis: "Not indexed.", NoIndex: true},
+		{Slug: "second", Title: "Second", Thesis: "Another *note*."},
+	}
+	if err := generateLLMsTxt(cfg, notes); err != nil {
+		t.Fatalf("generateLLMsTxt returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(cfg.OutputDir, "llms.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# Example Notes
+
+> Notes on examples
+
+## Notes
+
+- [First](https://notes.example.com/first/): Spans two lines.
+- [Second](https://notes.example.com/second/): Another *note*.
+`
+	if string(data) != want {
+		t.Errorf("llms.txt is\n%s\nwant\n%s", data, want)
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// defaultTheme is applied to notes without a theme and is always valid
+const defaultTheme = "default"
+
+// themeSelector matches the note detail rules that define a theme in CSS
+var themeSelector = regexp.MustCompile(`\.note-detail\.([a-z0-9-]+)`)
+
+// availableThemes returns the theme names styled by the CSS files in the
+// static roots, plus the default theme
+func availableThemes(roots []staticRoot) (map[string]bool, error) {
+	themes := map[string]bool{defaultTheme: true}
+	for _, root := range roots {
+		err := fs.WalkDir(root.FS, ".", func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() || !strings.HasSuffix(path, ".css") {
+				return nil
+			}
+
+			data, err := fs.ReadFile(root.FS, path)
+			if err != nil {
+				return err
+			}
+			for _, match := range themeSelector.FindAllStringSubmatch(string(data), -1) {
+				themes[match[1]] = true
+			}
+			return nil
+		})
+		if err != nil {
+			return themes, fmt.Errorf("%s: %w", root.Name, err)
+		}
+	}
+	return themes, nil
+}
+
+// checkTheme returns an error if theme is not one of themes
+func checkTheme(theme string, themes map[string]bool) error {
+	if themes[theme] {
+		return nil
+	}
+
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("unknown theme %q, should be one of %s", theme, strings.Join(names, ", "))
+}
+
+// checkThemes returns an error naming the first note with an unknown theme
+func checkThemes(notes []Note, themes map[string]bool) error {
+	for _, note := range notes {
+		if err := checkTheme(note.Theme, themes); err != nil {
+			return fmt.Errorf("note %s: %w", note.Slug, err)
+		}
+	}
+	return nil
+}
+
+// themeTemplatePattern matches note templates that override the default for
+// a single theme
+const themeTemplatePattern = "templates/themes/*/note.html"
+
+// noteTemplates holds the default note template and any per-theme overrides
+type noteTemplates struct {
+	base   *template.Template
+	themes map[string]*template.Template
+}
+
+// forTheme returns the note template for theme, falling back to the default
+func (t noteTemplates) forTheme(theme string) *template.Template {
+	if tmpl, ok := t.themes[theme]; ok {
+		return tmpl
+	}
+	return t.base
+}
+
+// parseNoteTemplates parses the default note template and every theme
+// override found under templates/themes in fsys
+func parseNoteTemplates(fsys fs.FS, funcs template.FuncMap) (noteTemplates, error) {
+	base, err := parseTemplate(fsys, funcs, "templates/note.html", "templates/footer.html")
+	if err != nil {
+		return noteTemplates{}, err
+	}
+
+	files, err := fs.Glob(fsys, themeTemplatePattern)
+	if err != nil {
+		return noteTemplates{}, err
+	}
+	themes := make(map[string]*template.Template, len(files))
+	for _, file := range files {
+		tmpl, err := parseTemplate(fsys, funcs, file, "templates/footer.html")
+		if err != nil {
+			return noteTemplates{}, err
+		}
+		themes[path.Base(path.Dir(file))] = tmpl
+	}
+	return noteTemplates{base: base, themes: themes}, nil
+}
+
+// ThemeGroup is the set of notes sharing a theme
+type ThemeGroup struct {
+	Theme string
+	Notes []Note
+}
+
+// ThemeIndexData holds data for the theme index template
+type ThemeIndexData struct {
+	Lang      string
+	CSP       string
+	SiteTitle string
+	Groups    []ThemeGroup
+	Footer    FooterData
+}
+
+// themeGroups buckets notes by theme. Groups are ordered by theme name, and
+// notes within a group are sorted by title case-insensitively.
+func themeGroups(notes []Note) []ThemeGroup {
+	buckets := make(map[string][]Note)
+	for _, note := range notes {
+		buckets[note.Theme] = append(buckets[note.Theme], note)
+	}
+
+	groups := make([]ThemeGroup, 0, len(buckets))
+	for theme, bucket := range buckets {
+		sort.SliceStable(bucket, func(i, j int) bool {
+			ti, tj := strings.ToLower(bucket[i].Title), strings.ToLower(bucket[j].Title)
+			if ti != tj {
+				return ti < tj
+			}
+			return bucket[i].Slug < bucket[j].Slug
+		})
+		groups = append(groups, ThemeGroup{Theme: theme, Notes: bucket})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Theme < groups[j].Theme
+	})
+	return groups
+}
+
+// generateThemeIndex writes the page grouping notes by theme to
+// themes/index.html
+func generateThemeIndex(cfg Config, tmpl *template.Template, notes []Note) error {
+	dir := filepath.Join(cfg.OutputDir, "themes")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	f, err := os.Create(filepath.Join(dir, "index.html"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	data := ThemeIndexData{
+		Lang:      cfg.DefaultLang,
+		CSP:       cfg.CSP,
+		SiteTitle: cfg.SiteTitle,
+		Groups:    themeGroups(publishedNotes(notes)),
+		Footer:    newFooterData(cfg),
+	}
+	return tmpl.Execute(f, data)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestAvailableThemes(t *testing.T) {
+	roots := []staticRoot{
+		{Name: "static", FS: fstest.MapFS{
+			"style.css": {Data: []byte(".note-detail.blue { color: blue; }\n.note-card.green {}\n")},
+		}},
+		{Name: "shared", FS: fstest.MapFS{
+			"themes/extra.css": {Data: []byte(".note-detail.night-sky { color: black; }")},
+		}},
+	}
+
+	themes, err := availableThemes(roots)
+	if err != nil {
+		t.Fatalf("availableThemes returned error: %v", err)
+	}
+	for _, theme := range []string{"default", "blue", "night-sky"} {
+		if !themes[theme] {
+			t.Errorf("expected theme %q to be available", theme)
+		}
+	}
+	if themes["green"] {
+		t.Error("themes should only be derived from note detail rules")
+	}
+
+	err = checkThemes([]Note{{Slug: "ok", Theme: "blue"}, {Slug: "bad-note", Theme: "pink"}}, themes)
+	if err == nil || !strings.Contains(err.Error(), "bad-note") {
+		t.Errorf("expected an error naming the offending note, got %v", err)
+	}
+}
+
+func TestParseNoteTemplatesThemeOverride(t *testing.T) {
+	fsys := fstest.MapFS{
+		"templates/note.html":             {Data: []byte(`base {{.Title}}`)},
+		"templates/footer.html":           {Data: []byte(`footer`)},
+		"templates/themes/blue/note.html": {Data: []byte(`blue {{.Title}}`)},
+	}
+
+	tmpls, err := parseNoteTemplates(fsys, nil)
+	if err != nil {
+		t.Fatalf("parseNoteTemplates returned error: %v", err)
+	}
+
+	for theme, want := range map[string]string{"blue": "blue Note", "default": "base Note", "green": "base Note"} {
+		var b strings.Builder
+		if err := tmpls.forTheme(theme).Execute(&b, Note{Title: "Note"}); err != nil {
+			t.Fatal(err)
+		}
+		if b.String() != want {
+			t.Errorf("theme %q rendered %q, want %q", theme, b.String(), want)
+		}
+	}
+}
+
+func TestGenerateThemeIndex(t *testing.T) {
+	tmpl, err := parseTemplate(siteFS, templateFuncs(Config{}, nil), "templates/themes.html", "templates/footer.html")
+	if err != nil {
+		t.Fatalf("Failed to parse themes template: %v", err)
+	}
+
+	cfg := testConfig(t)
+	notes := []Note{
+		{Slug: "zebra", Title: "Zebra", Theme: "blue"},
+		{Slug: "plain", Title: "Plain", Theme: "default"},
+		{Slug: "apple", Title: "apple", Theme: "blue"},
+		{Slug: "draft", Title: "Draft", Theme: "green", Draft: true},
+	}
+
+	groups := themeGroups(publishedNotes(notes))
+	if len(groups) != 2 || groups[0].Theme != "blue" || groups[1].Theme != "default" {
+		t.Fatalf("unexpected theme groups %+v", groups)
+	}
+	if groups[0].Notes[0].Slug != "apple" || groups[0].Notes[1].Slug != "zebra" {
+		t.Errorf("notes within a theme should be sorted by title, got %s, %s", groups[0].Notes[0].Slug, groups[0].Notes[1].Slug)
+	}
+
+	if err := generateThemeIndex(cfg, tmpl, notes); err != nil {
+		t.Fatalf("generateThemeIndex failed: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(cfg.OutputDir, "themes", "index.html"))
+	if err != nil {
+		t.Fatalf("expected theme index: %v", err)
+	}
+	html := string(content)
+	if !strings.Contains(html, `id="theme-blue"`) || !strings.Contains(html, `id="theme-default"`) {
+		t.Errorf("theme index should have a section per theme:\n%s", html)
+	}
+	if strings.Contains(html, "/draft/") {
+		t.Error("theme index should not list drafts")
+	}
+}
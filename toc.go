@@ -0,0 +1,90 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// TOCEntry is a single table of contents link to a section of a note page
+type TOCEntry struct {
+	ID    string
+	Title string
+}
+
+// noteTOC returns the table of contents for a note, one entry per section
+// present on the page, along with a map from section key to anchor ID used
+// by the note template. It returns nil values when the note has not opted in.
+func noteTOC(note Note) ([]TOCEntry, map[string]string) {
+	if !note.TOC {
+		return nil, nil
+	}
+
+	sections := []struct {
+		key     string
+		title   string
+		present bool
+	}{
+		{"thesis", "Thesis", note.Thesis != ""},
+		{"quote", "Quote", note.Quote != ""},
+		{"diagram", "Diagram", note.Diagram != ""},
+		{"bullets", "Key Points", len(note.Bullets) > 0},
+		{"example", "Example", note.Example != ""},
+		{"body", "Details", note.Body != ""},
+		{"links", "Links", len(note.Links) > 0},
+		{"footnotes", "Footnotes", len(note.FootnotesHTML) > 0},
+	}
+
+	var entries []TOCEntry
+	anchors := make(map[string]string)
+	seen := make(map[string]bool)
+	for _, s := range sections {
+		if !s.present {
+			continue
+		}
+		id := anchorID(s.title, seen)
+		entries = append(entries, TOCEntry{ID: id, Title: s.title})
+		anchors[s.key] = id
+	}
+	return entries, anchors
+}
+
+// bulletID returns the anchor ID of the bullet at index i of a note: b-1,
+// b-2, and so on in order, so a link to a bullet keeps working as long as it
+// isn't moved. The IDs can't collide with section anchors, which never
+// start with a single letter and hyphen.
+func bulletID(i int) string {
+	return "b-" + strconv.Itoa(i+1)
+}
+
+// anchorID converts text into a slug-safe anchor ID that is unique among the
+// IDs recorded in seen, appending a numeric suffix on collision.
+func anchorID(text string, seen map[string]bool) string {
+	base := slugify(text)
+	if base == "" {
+		base = "section"
+	}
+
+	id := base
+	for n := 2; seen[id]; n++ {
+		id = base + "-" + strconv.Itoa(n)
+	}
+	seen[id] = true
+	return id
+}
+
+// slugify lowercases text and joins its runs of ASCII letters and digits with
+// single hyphens, dropping everything else
+func slugify(text string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(text) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
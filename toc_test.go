@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestAnchorID(t *testing.T) {
+	seen := make(map[string]bool)
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Key Points", "key-points"},
+		{"  Hello, World!  ", "hello-world"},
+		{"Key Points", "key-points-2"},
+		{"key points", "key-points-3"},
+		{"!!!", "section"},
+		{"???", "section-2"},
+	}
+	for _, tt := range tests {
+		if got := anchorID(tt.text, seen); got != tt.want {
+			t.Errorf("anchorID(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestNoteTOC(t *testing.T) {
+	note := Note{
+		Thesis:  "A thesis",
+		Bullets: []string{"one", "two"},
+		Links:   []Link{{Label: "Go", URL: "https://go.dev"}},
+	}
+
+	if entries, anchors := noteTOC(note); entries != nil || anchors != nil {
+		t.Errorf("expected no TOC when not opted in, got %v %v", entries, anchors)
+	}
+
+	note.TOC = true
+	entries, anchors := noteTOC(note)
+	want := []TOCEntry{
+		{ID: "thesis", Title: "Thesis"},
+		{ID: "key-points", Title: "Key Points"},
+		{ID: "links", Title: "Links"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("expected %d entries, got %v", len(want), entries)
+	}
+	for i := range want {
+		if entries[i] != want[i] {
+			t.Errorf("entry %d = %v, want %v", i, entries[i], want[i])
+		}
+	}
+	if anchors["bullets"] != "key-points" {
+		t.Errorf("expected bullets anchor key-points, got %q", anchors["bullets"])
+	}
+	if _, ok := anchors["example"]; ok {
+		t.Error("expected no anchor for missing example section")
+	}
+}
+
+func TestNotePageBulletAnchors(t *testing.T) {
+	tmpl, err := parseTemplate(siteFS, templateFuncs(Config{}, nil), "templates/note.html", "templates/footer.html")
+	if err != nil {
+		t.Fatalf("Failed to parse note template: %v", err)
+	}
+
+	note := Note{Slug: "example", Title: "Example", Bullets: []string{"First", "Second"}}
+	if err := renderNoteMarkdown(&note); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, NotePageData{Note: note}); err != nil {
+		t.Fatalf("Failed to execute note template: %v", err)
+	}
+	for _, want := range []string{
+		`<li id="b-1">First <a href="#b-1" class="bullet-link"`,
+		`<li id="b-2">Second <a href="#b-2" class="bullet-link"`,
+		`<script defer src="/bullets.js"></script>`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("rendered note page does not contain %s", want)
+		}
+	}
+	if strings.Contains(buf.String(), "<script>") {
+		t.Error("expected the copy link handler to be loaded from a static file, not inline")
+	}
+}
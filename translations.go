@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Alternate is a translation of a page in another language
+type Alternate struct {
+	Lang string
+	URL  string
+}
+
+// noteAlternates returns the hreflang alternates for note: the note itself
+// and each of its translations, sorted by language. It returns nil for notes
+// without translations.
+func noteAlternates(cfg Config, note Note) []Alternate {
+	if len(note.Translations) == 0 {
+		return nil
+	}
+
+	alternates := []Alternate{{Lang: note.Lang, URL: noteCanonicalURL(cfg, note.Slug)}}
+	for lang, slug := range note.Translations {
+		alternates = append(alternates, Alternate{Lang: lang, URL: noteCanonicalURL(cfg, slug)})
+	}
+	sort.Slice(alternates, func(i, j int) bool {
+		return alternates[i].Lang < alternates[j].Lang
+	})
+	return alternates
+}
+
+// checkTranslations returns an error when a note lists a translation to a
+// slug that does not exist, to itself, or in its own language
+func checkTranslations(notes []Note) error {
+	slugs := make(map[string]bool, len(notes))
+	for _, note := range notes {
+		slugs[note.Slug] = true
+	}
+
+	for _, note := range notes {
+		langs := make([]string, 0, len(note.Translations))
+		for lang := range note.Translations {
+			langs = append(langs, lang)
+		}
+		sort.Strings(langs)
+
+		for _, lang := range langs {
+			slug := note.Translations[lang]
+			switch {
+			case lang == note.Lang:
+				return fmt.Errorf("%s lists a %q translation in its own language", note.Source, lang)
+			case slug == note.Slug:
+				return fmt.Errorf("%s lists itself as its %q translation", note.Source, lang)
+			case !slugs[slug]:
+				return fmt.Errorf("%s lists %q translation %q, which is not a note", note.Source, lang, slug)
+			}
+		}
+	}
+	return nil
+}
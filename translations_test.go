@@ -0,0 +1,85 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckTranslations(t *testing.T) {
+	tests := []struct {
+		name    string
+		note    Note
+		wantErr string
+	}{
+		{"valid", Note{Slug: "hello", Lang: "en", Translations: map[string]string{"de": "hallo"}}, ""},
+		{"missing slug", Note{Slug: "hello", Lang: "en", Translations: map[string]string{"fr": "bonjour"}}, `"bonjour", which is not a note`},
+		{"own language", Note{Slug: "hello", Lang: "en", Translations: map[string]string{"en": "hallo"}}, "in its own language"},
+		{"self", Note{Slug: "hello", Lang: "en", Translations: map[string]string{"de": "hello"}}, "lists itself"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.note.Source = "content/hello.yaml"
+			notes := []Note{tt.note, {Slug: "hallo", Lang: "de"}}
+			err := checkTranslations(notes)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestTranslationAlternates(t *testing.T) {
+	cfg := testConfig(t)
+	notes := []Note{
+		{Slug: "hello", Lang: "en", Translations: map[string]string{"de": "hallo", "fr": "bonjour"}},
+		{Slug: "hallo", Lang: "de", Translations: map[string]string{"en": "hello", "fr": "bonjour"}},
+		{Slug: "bonjour", Lang: "fr", Translations: map[string]string{"en": "hello", "de": "hallo"}},
+		{Slug: "untranslated", Lang: "en"},
+	}
+
+	alternates := noteAlternates(cfg, notes[0])
+	want := []Alternate{
+		{Lang: "de", URL: "https://notes.example.com/hallo/"},
+		{Lang: "en", URL: "https://notes.example.com/hello/"},
+		{Lang: "fr", URL: "https://notes.example.com/bonjour/"},
+	}
+	if len(alternates) != len(want) {
+		t.Fatalf("expected %d alternates, got %v", len(want), alternates)
+	}
+	for i := range want {
+		if alternates[i] != want[i] {
+			t.Errorf("alternate %d = %v, want %v", i, alternates[i], want[i])
+		}
+	}
+	if alt := noteAlternates(cfg, notes[3]); alt != nil {
+		t.Errorf("expected no alternates for an untranslated note, got %v", alt)
+	}
+
+	if err := generateSitemap(cfg, notes, defaultSitemapMaxURLs); err != nil {
+		t.Fatalf("generateSitemap returned error: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(cfg.OutputDir, "sitemap.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sitemap := string(content)
+	for _, want := range []string{
+		`xmlns:xhtml="http://www.w3.org/1999/xhtml"`,
+		`<xhtml:link rel="alternate" hreflang="de" href="https://notes.example.com/hallo/"></xhtml:link>`,
+	} {
+		if !strings.Contains(sitemap, want) {
+			t.Errorf("sitemap missing %s", want)
+		}
+	}
+	if n := strings.Count(sitemap, "<xhtml:link"); n != 9 {
+		t.Errorf("expected 9 alternate links (3 per translated note), got %d", n)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// URL styles select which files are written for each note and which form of
+// its URL the site links to
+const (
+	// urlStyleDirectory writes slug/index.html and links to /slug/
+	urlStyleDirectory = "directory"
+	// urlStyleExtension writes slug.html and links to /slug, for hosts that
+	// serve slug.html at /slug
+	urlStyleExtension = "extension"
+	// urlStyleBoth writes both files and links to /slug/
+	urlStyleBoth = "both"
+)
+
+// validateURLStyle returns an error unless style is a supported URL style
+func validateURLStyle(style string) error {
+	switch style {
+	case urlStyleDirectory, urlStyleExtension, urlStyleBoth:
+		return nil
+	}
+	return fmt.Errorf("unsupported url_style %q (supported: %s, %s, %s)", style, urlStyleDirectory, urlStyleExtension, urlStyleBoth)
+}
+
+// notePath returns the site path of the note with slug, without the base path
+func notePath(cfg Config, slug string) string {
+	if cfg.URLStyle == urlStyleExtension {
+		return "/" + slug
+	}
+	return "/" + slug + "/"
+}
+
+// noteCanonicalURL returns the absolute URL of the note with slug
+func noteCanonicalURL(cfg Config, slug string) string {
+	return cfg.BaseURL + notePath(cfg, slug)
+}
+
+// notePageFiles returns the files written for the note with slug, relative
+// to the output directory
+func notePageFiles(cfg Config, slug string) []string {
+	slugDir := filepath.FromSlash(slug)
+	switch cfg.URLStyle {
+	case urlStyleDirectory:
+		return []string{filepath.Join(slugDir, "index.html")}
+	case urlStyleExtension:
+		return []string{slugDir + ".html"}
+	}
+	return []string{slugDir + ".html", filepath.Join(slugDir, "index.html")}
+}
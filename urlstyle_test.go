@@ -0,0 +1,85 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestURLStyles(t *testing.T) {
+	tests := []struct {
+		style   string
+		path    string
+		written []string
+		absent  []string
+	}{
+		{urlStyleBoth, "/go/concurrency/", []string{"go/concurrency.html", "go/concurrency/index.html"}, nil},
+		{urlStyleDirectory, "/go/concurrency/", []string{"go/concurrency/index.html"}, []string{"go/concurrency.html"}},
+		{urlStyleExtension, "/go/concurrency", []string{"go/concurrency.html"}, []string{"go/concurrency/index.html"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.style, func(t *testing.T) {
+			cfg := testConfig(t)
+			cfg.URLStyle = tt.style
+			note := Note{Slug: "go/concurrency", Title: "Concurrency"}
+
+			if got := notePath(cfg, note.Slug); got != tt.path {
+				t.Errorf("notePath() = %q, want %q", got, tt.path)
+			}
+
+			tmpl, err := parseTemplate(siteFS, templateFuncs(cfg, nil), "templates/note.html", "templates/footer.html")
+			if err != nil {
+				t.Fatalf("Failed to parse note template: %v", err)
+			}
+			data := NotePageData{Note: note, CanonicalURL: noteCanonicalURL(cfg, note.Slug)}
+			if err := generateNotePage(cfg, tmpl, data); err != nil {
+				t.Fatalf("generateNotePage returned error: %v", err)
+			}
+			for _, file := range tt.written {
+				content, err := os.ReadFile(filepath.Join(cfg.OutputDir, filepath.FromSlash(file)))
+				if err != nil {
+					t.Errorf("expected %s to be written: %v", file, err)
+					continue
+				}
+				want := `<link rel="canonical" href="https://notes.example.com` + tt.path + `">`
+				if !strings.Contains(string(content), want) {
+					t.Errorf("%s does not contain %s", file, want)
+				}
+			}
+			for _, file := range tt.absent {
+				if _, err := os.Stat(filepath.Join(cfg.OutputDir, filepath.FromSlash(file))); err == nil {
+					t.Errorf("%s should not be written", file)
+				}
+			}
+
+			if err := generateSitemap(cfg, []Note{note}, defaultSitemapMaxURLs); err != nil {
+				t.Fatalf("generateSitemap returned error: %v", err)
+			}
+			var sitemap Sitemap
+			readXMLFile(t, filepath.Join(cfg.OutputDir, "sitemap.xml"), &sitemap)
+			if last := sitemap.URLs[len(sitemap.URLs)-1].Loc; last != "https://notes.example.com"+tt.path {
+				t.Errorf("sitemap lists %q, want the %s form", last, tt.style)
+			}
+		})
+	}
+}
+
+func TestLoadConfigURLStyle(t *testing.T) {
+	t.Setenv("BASEURL", "https://notes.example.com")
+	missing := filepath.Join(t.TempDir(), "missing.yaml")
+
+	cfg, err := loadConfig(missing)
+	if err != nil {
+		t.Fatalf("loadConfig returned error: %v", err)
+	}
+	if cfg.URLStyle != urlStyleBoth {
+		t.Errorf("expected url_style to default to %q, got %q", urlStyleBoth, cfg.URLStyle)
+	}
+
+	t.Setenv("URL_STYLE", "pretty")
+	if _, err := loadConfig(missing); err == nil {
+		t.Error("expected an error for an unsupported url_style")
+	}
+}
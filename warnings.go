@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// reporter prints build warnings and counts them so that strict builds can
+// fail once every warning has been shown
+type reporter struct {
+	out    io.Writer
+	strict bool
+	count  int
+}
+
+// newReporter returns a reporter printing to stderr
+func newReporter(strict bool) *reporter {
+	return &reporter{out: os.Stderr, strict: strict}
+}
+
+// Warnf prints a warning formatted like fmt.Printf
+func (r *reporter) Warnf(format string, args ...any) {
+	r.count++
+	fmt.Fprintf(r.out, "Warning: "+format+"\n", args...)
+}
+
+// Err returns an error in strict mode when any warnings were reported
+func (r *reporter) Err() error {
+	if !r.strict || r.count == 0 {
+		return nil
+	}
+	if r.count == 1 {
+		return fmt.Errorf("1 warning reported in strict mode")
+	}
+	return fmt.Errorf("%d warnings reported in strict mode", r.count)
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReporter(t *testing.T) {
+	for _, strict := range []bool{false, true} {
+		var out bytes.Buffer
+		r := &reporter{out: &out, strict: strict}
+		if err := r.Err(); err != nil {
+			t.Errorf("strict=%v: expected no error without warnings, got %v", strict, err)
+		}
+
+		r.Warnf("unreachable link %s", "https://example.com/gone")
+		r.Warnf("%s overrides a file", "style.css")
+		if got := out.String(); got != "Warning: unreachable link https://example.com/gone\nWarning: style.css overrides a file\n" {
+			t.Errorf("strict=%v: unexpected output %q", strict, got)
+		}
+
+		err := r.Err()
+		switch {
+		case strict && (err == nil || !strings.Contains(err.Error(), "2 warnings")):
+			t.Errorf("expected strict mode to fail with the warning count, got %v", err)
+		case !strict && err != nil:
+			t.Errorf("expected lenient mode to succeed, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDirs are the source directories that trigger a rebuild when changed
+var watchDirs = []string{"content", "templates", "static"}
+
+// watchDebounce is how long to wait for further changes before rebuilding
+const watchDebounce = 200 * time.Millisecond
+
+// watchAndRebuild builds the site from the directories on disk and rebuilds
+// whenever a file under them or the configured static_dirs changes. It runs
+// until the watcher fails.
+func watchAndRebuild(cfg Config) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("creating watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	dirs := append(append([]string{}, watchDirs...), cfg.StaticDirs...)
+	for _, dir := range dirs {
+		if err := watchRecursive(watcher, dir); err != nil {
+			return fmt.Errorf("watching %s: %w", dir, err)
+		}
+	}
+
+	diskFS := os.DirFS(".")
+	rebuild := func() {
+		if err := run(cfg, diskFS); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+	}
+
+	rebuild()
+	fmt.Printf("\nWatching %v for changes...\n", dirs)
+
+	// Coalesce bursts of events, such as an editor writing a file in several
+	// steps, into a single rebuild
+	var trigger string
+	timer := time.NewTimer(watchDebounce)
+	timer.Stop()
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			// Permission and timestamp changes don't affect the output
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			// Watch directories created after startup
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := watchRecursive(watcher, event.Name); err != nil {
+						fmt.Fprintf(os.Stderr, "Error: watching %s: %v\n", event.Name, err)
+					}
+				}
+			}
+			trigger = fmt.Sprintf("%s %s", event.Op, event.Name)
+			timer.Reset(watchDebounce)
+		case <-timer.C:
+			fmt.Printf("\nRebuilding after %s\n", trigger)
+			rebuild()
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			return fmt.Errorf("watching files: %w", err)
+		}
+	}
+}
+
+// watchRecursive adds dir and all of its subdirectories to watcher
+func watchRecursive(watcher *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return watcher.Add(path)
+		}
+		return nil
+	})
+}